@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+)
+
+func runCheck(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: cruciterm %s [flags] %s\n\n%s.\n", cmd.name, cmd.args, cmd.summary)
+		fmt.Fprintf(fs.Output(), "Exits with status 0 when solved and %d when squares are empty or wrong.\n\nFlags:\n", exitUnsolved)
+		fs.PrintDefaults()
+	}
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+
+	_, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+
+	puz, err := loadPuzzle(fs.Arg(0))
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if puz.Locked {
+		return fail(cmd, fmt.Errorf("%s: the solution is locked; run \"cruciterm unlock\" first", fs.Arg(0)))
+	}
+
+	var squares, filled, wrong int
+	for i := range len(puz.Answer) {
+		if puz.Answer[i] == '.' {
+			continue
+		}
+		squares++
+		if puz.CurrentState[i] == '-' {
+			continue
+		}
+		filled++
+		if puz.CurrentState[i] != puz.Answer[i] {
+			wrong++
+		}
+	}
+
+	if filled == squares && wrong == 0 {
+		fmt.Println("solved")
+		return exitOK
+	}
+	fmt.Printf("%d of %d squares filled, %d incorrect\n", filled, squares, wrong)
+	return exitUnsolved
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var appName = "cruciterm"
+
+type Config struct {
+	Theme       string          `json:"theme,omitempty"`
+	KeyMap      string          `json:"keymap,omitempty"`
+	LogFile     string          `json:"logFile,omitempty"`
+	LogLevel    string          `json:"logLevel,omitempty"`
+	PuzzleDirs  []string        `json:"puzzleDirs,omitempty"`
+	Preferences map[string]bool `json:"preferences,omitempty"`
+	Sources     []Source        `json:"sources,omitempty"`
+	WordList    string          `json:"wordList,omitempty"`
+}
+
+// Source is a site that publishes a puzzle each day at a URL that includes
+// the date.
+type Source struct {
+	Name string `json:"name"`
+	// URL is a template such as
+	// "https://example.com/puzzles/{yyyy}-{mm}-{dd}.puz".
+	URL string `json:"url"`
+	// Days lists the weekdays puzzles come out, such as "mon". Every day when
+	// empty.
+	Days []string `json:"days,omitempty"`
+	// Since is the date of the first puzzle, as YYYY-MM-DD.
+	Since string `json:"since,omitempty"`
+}
+
+// Load reads the config file at path. An empty path loads the default config
+// file, which is allowed to be missing.
+func Load(path string) (Config, error) {
+	var config Config
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(ConfigDir(), "config.json")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ConfigDir is where cruciterm looks for its config file, following the XDG
+// base directory spec.
+func ConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, appName)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, appName)
+	}
+	return filepath.Join(".", "."+appName)
+}
+
+// StateDir is where cruciterm keeps solving progress and other data that
+// should survive restarts, following the XDG base directory spec.
+func StateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, appName)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", appName)
+	}
+	return filepath.Join(".", "."+appName)
+}
+
+// DataDir is where cruciterm keeps user data such as puzzles, following the
+// XDG base directory spec.
+func DataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, appName)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", appName)
+	}
+	return filepath.Join(".", "."+appName)
+}
+
+// DefaultPuzzleDirs are searched for puzzles when the config names none.
+func DefaultPuzzleDirs() []string {
+	return []string{filepath.Join(DataDir(), "puzzles"), "puzzles"}
+}
+
+// SourcesDir is where puzzles downloaded from sources are kept, one
+// directory per source.
+func SourcesDir() string {
+	return filepath.Join(DataDir(), "sources")
+}
+
+// LogPath is the default log file.
+func LogPath() string {
+	return filepath.Join(StateDir(), appName+".log")
+}
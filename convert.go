@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+)
+
+func runConvert(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	output := fs.String("o", "", "output file when converting a single puzzle; its extension selects the format")
+	outputDir := fs.String("dir", "", "directory to write converted puzzles to (default next to each input)")
+	format := fs.String("format", "puz", "output format: "+strings.Join(formatNames(), ", "))
+	force := fs.Bool("force", false, "overwrite existing output files")
+	if code, ok := parseFlags(fs, args, -1); !ok {
+		return code
+	}
+	inputs := fs.Args()
+	if len(inputs) == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	if *output != "" && (len(inputs) > 1 || *outputDir != "") {
+		fmt.Fprintln(os.Stderr, "cruciterm convert: -o takes a single input and cannot be combined with -dir")
+		return exitUsage
+	}
+	ext := "." + strings.TrimPrefix(strings.ToLower(*format), ".")
+	if !slices.Contains(loader.Formats, ext) {
+		fmt.Fprintf(os.Stderr, "cruciterm convert: unknown format %q; choose one of %s\n", *format, strings.Join(formatNames(), ", "))
+		return exitUsage
+	}
+
+	_, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+
+	if *outputDir != "" {
+		if err := os.MkdirAll(*outputDir, 0755); err != nil {
+			return fail(cmd, err)
+		}
+	}
+
+	code := exitOK
+	for _, input := range inputs {
+		path := *output
+		if path == "" {
+			base := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input)) + ext
+			dir := *outputDir
+			if dir == "" {
+				dir = filepath.Dir(input)
+			}
+			path = filepath.Join(dir, base)
+		}
+		if err := convertPuzzle(input, path, *force); err != nil {
+			code = fail(cmd, err)
+		}
+	}
+	return code
+}
+
+func convertPuzzle(input, output string, force bool) error {
+	if filepath.Clean(input) == filepath.Clean(output) {
+		return fmt.Errorf("%s: refusing to overwrite the input; choose another format or output", input)
+	}
+	if _, err := os.Stat(output); err == nil && !force {
+		return fmt.Errorf("%s already exists; use -force to overwrite it", output)
+	}
+
+	puz, err := loadPuzzle(input)
+	if err != nil {
+		return err
+	}
+	for _, lost := range loader.LossyFields(output, &puz) {
+		fmt.Fprintf(os.Stderr, "cruciterm convert: warning: %s: %s will be lost in %s\n", input, lost, filepath.Ext(output))
+	}
+	if err := loader.SaveFile(output, &puz); err != nil {
+		return fmt.Errorf("%s: %w", output, err)
+	}
+	fmt.Printf("%s -> %s\n", input, output)
+	return nil
+}
+
+func formatNames() []string {
+	names := make([]string, len(loader.Formats))
+	for i, ext := range loader.Formats {
+		names[i] = strings.TrimPrefix(ext, ".")
+	}
+	return names
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/tylerwgrass/cruciterm/coop"
+	"github.com/tylerwgrass/cruciterm/logger"
+	"github.com/tylerwgrass/cruciterm/progress"
+	"github.com/tylerwgrass/cruciterm/solver"
+)
+
+func defaultPlayerName() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return cmp.Or(os.Getenv("USER"), "player")
+}
+
+func runHost(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	var options solverOptions
+	options.register(fs)
+	listen := fs.String("listen", coop.DefaultAddress, "address to share the puzzle on, or unix:<path> for a unix socket")
+	name := fs.String("name", defaultPlayerName(), "name shown to other players")
+	resume := fs.Bool("resume", false, "continue from saved progress instead of the fill stored in the file")
+	race := fs.Bool("race", false, "race on separate grids instead of solving together")
+	checkPenalty := fs.Duration("check-penalty", coop.DefaultPenalties.Check, "time added in a race for each check")
+	revealPenalty := fs.Duration("reveal-penalty", coop.DefaultPenalties.Reveal, "time added in a race for each reveal")
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+	if *race && *resume {
+		fmt.Fprintf(os.Stderr, "cruciterm %s: -resume cannot be used with -race\n", cmd.name)
+		return exitUsage
+	}
+
+	cfg, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if err := options.setup(cfg); err != nil {
+		return fail(cmd, err)
+	}
+
+	puz, err := loadPuzzle(fs.Arg(0))
+	if err != nil {
+		return fail(cmd, err)
+	}
+	var saved progress.Progress
+	if *resume {
+		saved, err = progress.DefaultStore().Load(&puz)
+		if err == nil {
+			puz.CurrentState = saved.State
+		} else if !errors.Is(err, progress.ErrNoProgress) {
+			return fail(cmd, err)
+		}
+	}
+
+	network, address := coop.ParseAddress(*listen)
+	var server *coop.Server
+	if *race {
+		// Everyone races from an empty grid.
+		puz.CurrentState = strings.Map(func(r rune) rune {
+			if r == '.' {
+				return r
+			}
+			return '-'
+		}, puz.Answer)
+		server, err = coop.ListenRace(network, address, &puz, coop.Penalties{Check: *checkPenalty, Reveal: *revealPenalty})
+	} else {
+		server, err = coop.Listen(network, address, &puz, saved.Elapsed)
+	}
+	if err != nil {
+		return fail(cmd, err)
+	}
+	defer server.Close()
+	go func() {
+		if err := server.Serve(); err != nil {
+			logger.Error("co-op server stopped", "err", err)
+		}
+	}()
+	logger.Info("hosting puzzle", "title", puz.Title, "addr", server.Addr(), "race", *race)
+
+	// The host plays through the server like everyone else, over loopback
+	// when it listens on every address.
+	address = server.Addr().String()
+	if network == "tcp" {
+		host, port, _ := net.SplitHostPort(address)
+		if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+			address = net.JoinHostPort("localhost", port)
+		}
+	}
+	client, err := coop.Dial(network, address, *name)
+	if err != nil {
+		return fail(cmd, err)
+	}
+	defer client.Close()
+	client.Address = *listen
+
+	results, err := solver.Run([]solver.Session{{
+		Puzzle:  &client.Puzzle,
+		Path:    fs.Arg(0),
+		Elapsed: client.Elapsed,
+		Coop:    client,
+	}}, solver.Options{HideTimer: options.noTimer, Preferences: options.preferences})
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if err := userStateIn("").saveProgress(&puz, results[0]); err != nil {
+		return fail(cmd, err)
+	}
+	return exitOK
+}
+
+func runJoin(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	var options solverOptions
+	options.register(fs)
+	name := fs.String("name", defaultPlayerName(), "name shown to other players")
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+
+	cfg, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if err := options.setup(cfg); err != nil {
+		return fail(cmd, err)
+	}
+
+	network, address := coop.ParseAddress(fs.Arg(0))
+	client, err := coop.Dial(network, address, *name)
+	if err != nil {
+		return fail(cmd, err)
+	}
+	defer client.Close()
+
+	results, err := solver.Run([]solver.Session{{
+		Puzzle:  &client.Puzzle,
+		Elapsed: client.Elapsed,
+		Coop:    client,
+	}}, solver.Options{HideTimer: options.noTimer, Preferences: options.preferences})
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if err := userStateIn("").saveProgress(&client.Puzzle, results[0]); err != nil {
+		return fail(cmd, err)
+	}
+	return exitOK
+}
+
+func runWatch(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	var options solverOptions
+	options.registerAppearance(fs)
+	name := fs.String("name", defaultPlayerName(), "name shown to the players")
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+
+	cfg, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if err := options.setup(cfg); err != nil {
+		return fail(cmd, err)
+	}
+
+	network, address := coop.ParseAddress(fs.Arg(0))
+	client, err := coop.Spectate(network, address, *name)
+	if err != nil {
+		return fail(cmd, err)
+	}
+	defer client.Close()
+
+	// Spectators keep nothing, as the puzzle isn't theirs.
+	_, err = solver.Run([]solver.Session{{
+		Puzzle:  &client.Puzzle,
+		Elapsed: client.Elapsed,
+		Coop:    client,
+	}}, solver.Options{Preferences: options.preferences})
+	if err != nil {
+		return fail(cmd, err)
+	}
+	return exitOK
+}
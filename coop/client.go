@@ -0,0 +1,126 @@
+package coop
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+var ErrHandshake = fmt.Errorf("co-op host did not welcome us")
+var ErrRefused = fmt.Errorf("co-op host turned us away")
+
+// Client is one player's connection to a server.
+type Client struct {
+	// Player is this client's ID.
+	Player int
+	Mode   Mode
+	// Puzzle is the shared puzzle, filled in as it was on joining.
+	Puzzle puzzle.PuzzleDefinition
+	// Elapsed is the time spent on the puzzle when joining.
+	Elapsed time.Duration
+	// Players were already connected when joining.
+	Players []Player
+	// Address is where the server was reached.
+	Address string
+	// Spectator is set when only watching, and Spectators counts everyone
+	// watching when joining.
+	Spectator  bool
+	Spectators int
+
+	conn      net.Conn
+	mu        sync.Mutex
+	enc       *json.Encoder
+	messages  chan Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Dial joins the server at address as name.
+func Dial(network, address, name string) (*Client, error) {
+	return dial(network, address, Message{Type: Hello, Name: name})
+}
+
+// Spectate follows the server at address as name, without playing.
+func Spectate(network, address, name string) (*Client, error) {
+	return dial(network, address, Message{Type: Hello, Name: name, Spectator: true})
+}
+
+func dial(network, address string, hello Message) (*Client, error) {
+	conn, err := net.DialTimeout(network, address, HANDSHAKE_TIMEOUT)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn, enc: json.NewEncoder(conn), Address: address, done: make(chan struct{})}
+	if err := c.Send(hello); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	dec := json.NewDecoder(conn)
+	var welcome Message
+	conn.SetReadDeadline(time.Now().Add(HANDSHAKE_TIMEOUT))
+	if err := dec.Decode(&welcome); err != nil || welcome.Type != Welcome {
+		conn.Close()
+		if welcome.Type == Refuse {
+			return nil, fmt.Errorf("%w: %s", ErrRefused, welcome.Name)
+		}
+		return nil, ErrHandshake
+	}
+	conn.SetReadDeadline(time.Time{})
+	puz, err := loader.DecodeJSON(welcome.Puzzle)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	puz.CurrentState = welcome.State
+
+	c.Player = welcome.Player
+	c.Mode = welcome.Mode
+	c.Puzzle = puz
+	c.Elapsed = welcome.Elapsed
+	c.Players = welcome.Players
+	c.Spectator = welcome.Spectator
+	c.Spectators = welcome.Spectators
+	c.messages = make(chan Message, 64)
+	go c.read(dec)
+	return c, nil
+}
+
+func (c *Client) read(dec *json.Decoder) {
+	defer close(c.messages)
+	for {
+		var msg Message
+		if err := dec.Decode(&msg); err != nil {
+			return
+		}
+		select {
+		case c.messages <- msg:
+		case <-c.done:
+			// Nobody is reading any more.
+			return
+		}
+	}
+}
+
+// Messages delivers everything the server sends after the welcome. It is
+// closed when the connection ends.
+func (c *Client) Messages() <-chan Message {
+	return c.messages
+}
+
+func (c *Client) Send(msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
+	return c.enc.Encode(msg)
+}
+
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+	return c.conn.Close()
+}
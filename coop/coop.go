@@ -0,0 +1,118 @@
+// Package coop lets several people solve one puzzle over a network, either
+// together or against each other.
+//
+// In co-op, a Server holds the shared grid and relays every change to its
+// clients, in the order it received them, so that all grids end up the same:
+// when two players fill the same square at once, whichever edit reached the
+// server last wins.
+//
+// In a race, every player fills in their own grid and only reports how far
+// along they are. The server times each racer from when they joined, so that
+// nobody is behind for joining late, and keeps the leaderboard.
+//
+// Spectators can follow a co-op puzzle without playing. They are sent
+// everything players are, but nothing they send is passed on.
+package coop
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// DefaultAddress is where hosts listen when no address is given.
+var DefaultAddress = ":7777"
+
+// Mode is how a server's players share its puzzle.
+type Mode string
+
+const (
+	Together Mode = "coop"
+	Race     Mode = "race"
+)
+
+type MessageType string
+
+const (
+	// Hello is the first message a client sends, with its player's name.
+	// Spectator is set by clients that only want to watch.
+	Hello MessageType = "hello"
+	// Welcome answers Hello with the puzzle and everything needed to catch up.
+	Welcome MessageType = "welcome"
+	// Refuse answers Hello instead of Welcome when the client can't join,
+	// with the reason in Name.
+	Refuse MessageType = "refuse"
+	Join   MessageType = "join"
+	Leave  MessageType = "leave"
+	// Edit changes one square. Revealed is set when the letter was revealed.
+	Edit MessageType = "edit"
+	// Cursor moves a player's cursor.
+	Cursor MessageType = "cursor"
+	// Marks lists squares a check found to be wrong.
+	Marks MessageType = "marks"
+	// Solved is sent once the shared grid matches the solution, with the
+	// final time.
+	Solved MessageType = "solved"
+	// Audience tells players how many Spectators are watching whenever it
+	// changes.
+	Audience MessageType = "audience"
+	// Progress reports how much of a racer's grid is filled and how much
+	// help they have used.
+	Progress MessageType = "progress"
+	// Finish claims a finished race with the racer's grid in State.
+	Finish MessageType = "finish"
+	// Standings is the leaderboard, sent whenever it changes.
+	Standings MessageType = "standings"
+)
+
+type Square struct {
+	Row int `json:"r"`
+	Col int `json:"c"`
+}
+
+// Message is one line of the protocol, encoded as JSON. Only the fields its
+// type uses are set.
+type Message struct {
+	Type     MessageType     `json:"type"`
+	Mode     Mode            `json:"mode,omitempty"`
+	Player   int             `json:"player,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Row      int             `json:"row,omitempty"`
+	Col      int             `json:"col,omitempty"`
+	Down     bool            `json:"down,omitempty"`
+	Fill     string          `json:"fill,omitempty"`
+	Revealed bool            `json:"revealed,omitempty"`
+	Squares  []Square        `json:"squares,omitempty"`
+	State    string          `json:"state,omitempty"`
+	Elapsed  time.Duration   `json:"elapsed,omitempty"`
+	Puzzle   json.RawMessage `json:"puzzle,omitempty"`
+	Players  []Player        `json:"players,omitempty"`
+	Percent  int             `json:"percent,omitempty"`
+	Checks   int             `json:"checks,omitempty"`
+	Reveals  int             `json:"reveals,omitempty"`
+	// Spectator and Spectators are used by Hello, Welcome and Audience.
+	Spectator  bool `json:"spectator,omitempty"`
+	Spectators int  `json:"spectators,omitempty"`
+	// Standings are sorted best first.
+	Standings []Standing `json:"standings,omitempty"`
+}
+
+// Player is someone connected to a server and where their cursor is. IDs
+// start at 1.
+type Player struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+	Down bool   `json:"down"`
+}
+
+// ParseAddress splits an address into a network and address for net.Listen
+// and net.Dial. Addresses starting with "unix:" are unix socket paths; any
+// other address is a TCP host and port.
+func ParseAddress(address string) (network, addr string) {
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", address
+}
@@ -0,0 +1,120 @@
+package coop
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+var ErrLockedRace = fmt.Errorf("puzzles with a locked solution cannot be raced")
+
+// Penalties are added to a racer's time for each check and reveal they use.
+type Penalties struct {
+	Check  time.Duration
+	Reveal time.Duration
+}
+
+var DefaultPenalties = Penalties{Check: 30 * time.Second, Reveal: time.Minute}
+
+// Standing is a racer's place on the leaderboard. Letters are never shared,
+// only how much of the grid is filled.
+type Standing struct {
+	Player    int    `json:"player"`
+	Name      string `json:"name"`
+	Percent   int    `json:"percent"`
+	Checks    int    `json:"checks"`
+	Reveals   int    `json:"reveals"`
+	Connected bool   `json:"connected"`
+	Finished  bool   `json:"finished"`
+	// Place is the order the racer finished in, starting at 1.
+	Place   int           `json:"place,omitempty"`
+	Elapsed time.Duration `json:"elapsed,omitempty"`
+	Penalty time.Duration `json:"penalty,omitempty"`
+	// joined is when the racer's clock started.
+	joined time.Time
+}
+
+// Total is the finishing time with penalties.
+func (s Standing) Total() time.Duration {
+	return s.Elapsed + s.Penalty
+}
+
+// ListenRace starts a race on puz at address. Each racer is timed from when
+// they join. Call Serve to accept racers.
+func ListenRace(network, address string, puz *puzzle.PuzzleDefinition, penalties Penalties) (*Server, error) {
+	if puz.Locked {
+		return nil, ErrLockedRace
+	}
+	s, err := Listen(network, address, puz, 0)
+	if err != nil {
+		return nil, err
+	}
+	s.mode = Race
+	s.penalties = penalties
+	s.standings = make(map[int]*Standing)
+	return s, nil
+}
+
+// receiveRace handles a racer's message. s.mu must be held.
+func (s *Server) receiveRace(p *peer, msg Message) {
+	standing := s.standings[p.ID]
+	if standing.Finished {
+		return
+	}
+	switch msg.Type {
+	case Progress:
+		standing.Percent = min(max(msg.Percent, 0), 100)
+		standing.countHelp(msg)
+	case Finish:
+		if msg.State != s.answer {
+			return
+		}
+		finished := 0
+		for _, other := range s.standings {
+			if other.Finished {
+				finished++
+			}
+		}
+		standing.Finished = true
+		standing.Place = finished + 1
+		standing.Percent = 100
+		standing.countHelp(msg)
+		standing.Elapsed = time.Since(standing.joined)
+	default:
+		return
+	}
+	standing.Penalty = time.Duration(standing.Checks)*s.penalties.Check + time.Duration(standing.Reveals)*s.penalties.Reveal
+	s.broadcast(s.standingsMessage(), 0)
+}
+
+// countHelp takes the checks and reveals a racer reports, never letting them
+// go down, as that would take back penalties.
+func (s *Standing) countHelp(msg Message) {
+	s.Checks = max(s.Checks, msg.Checks)
+	s.Reveals = max(s.Reveals, msg.Reveals)
+}
+
+// standingsMessage lists finished racers by total time, then everyone else
+// by how much they have filled.
+func (s *Server) standingsMessage() Message {
+	standings := make([]Standing, 0, len(s.standings))
+	for _, standing := range s.standings {
+		standings = append(standings, *standing)
+	}
+	slices.SortFunc(standings, func(a, b Standing) int {
+		switch {
+		case a.Finished && b.Finished:
+			return cmp.Or(cmp.Compare(a.Total(), b.Total()), cmp.Compare(a.Place, b.Place))
+		case a.Finished != b.Finished:
+			if a.Finished {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(cmp.Compare(b.Percent, a.Percent), cmp.Compare(a.Player, b.Player))
+	})
+	return Message{Type: Standings, Standings: standings}
+}
@@ -0,0 +1,89 @@
+package coop
+
+import (
+	"testing"
+	"time"
+)
+
+// standings waits for a leaderboard that ok accepts.
+func standings(t *testing.T, c *Client, ok func([]Standing) bool) []Standing {
+	t.Helper()
+	for {
+		if msg := next(t, c, Standings); ok(msg.Standings) {
+			return msg.Standings
+		}
+	}
+}
+
+func TestRaceStandings(t *testing.T) {
+	penalties := Penalties{Check: time.Minute, Reveal: time.Hour}
+	s, err := ListenRace("tcp", "127.0.0.1:0", testPuzzle(), penalties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+
+	a := dialTest(t, s, "alice")
+	time.Sleep(100 * time.Millisecond)
+	b := dialTest(t, s, "bob")
+	if b.Elapsed != 0 {
+		t.Errorf("bob joined with %v on the clock", b.Elapsed)
+	}
+
+	// Help that has been used can't be taken back.
+	a.Send(Message{Type: Progress, Percent: 50, Checks: 2, Reveals: 1})
+	a.Send(Message{Type: Progress, Percent: 60})
+	got := standings(t, b, func(standings []Standing) bool {
+		return len(standings) == 2 && standings[0].Percent == 60
+	})
+	if alice := got[0]; alice.Checks != 2 || alice.Reveals != 1 || alice.Penalty != 2*time.Minute+time.Hour {
+		t.Errorf("alice has %d checks, %d reveals and %v penalty, want 2, 1 and 2h1m", alice.Checks, alice.Reveals, alice.Penalty)
+	}
+	if bob := got[1]; bob.Name != "bob" || bob.Percent != 0 || bob.Finished {
+		t.Errorf("bob's standing is %+v", bob)
+	}
+
+	answer := testPuzzle().Answer
+	b.Send(Message{Type: Finish, State: answer})
+	standings(t, a, func(standings []Standing) bool { return standings[0].Finished })
+	a.Send(Message{Type: Finish, State: "X" + answer[1:]})
+	a.Send(Message{Type: Finish, State: answer})
+	got = standings(t, a, func(standings []Standing) bool {
+		return len(standings) == 2 && standings[0].Finished && standings[1].Finished
+	})
+	bob, alice := got[0], got[1]
+	if bob.Name != "bob" || bob.Place != 1 || alice.Place != 2 {
+		t.Fatalf("finished %+v, then %+v", bob, alice)
+	}
+	// Everyone is timed from when they joined, not from when the race began.
+	if alice.Elapsed < 100*time.Millisecond || bob.Elapsed >= alice.Elapsed {
+		t.Errorf("alice finished in %v and bob in %v", alice.Elapsed, bob.Elapsed)
+	}
+	if alice.Total() != alice.Elapsed+2*time.Minute+time.Hour {
+		t.Errorf("alice's total is %v with %v elapsed", alice.Total(), alice.Elapsed)
+	}
+
+	// Nothing changes once finished.
+	a.Send(Message{Type: Progress, Percent: 10, Checks: 9})
+	b.Send(Message{Type: Progress, Percent: 20})
+	time.Sleep(50 * time.Millisecond)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if got := s.standingsMessage().Standings; got[0].Percent != 100 || got[1].Checks != 2 {
+		t.Errorf("finished racers changed to %+v", got)
+	}
+}
+
+func TestRaceRefusesSpectators(t *testing.T) {
+	s, err := ListenRace("tcp", "127.0.0.1:0", testPuzzle(), DefaultPenalties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+	if c, err := Spectate("tcp", s.Addr().String(), "watcher"); err == nil {
+		c.Close()
+		t.Error("watching a race was allowed")
+	}
+}
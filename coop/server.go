@@ -0,0 +1,361 @@
+package coop
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/logger"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+var HANDSHAKE_TIMEOUT time.Duration = 10 * time.Second
+var WRITE_TIMEOUT time.Duration = 5 * time.Second
+
+// SEND_QUEUE_SIZE is how many messages can wait to be written to a client.
+// Clients that fall further behind are disconnected, as they could no longer
+// be kept in step, and so are those that take WRITE_TIMEOUT to take one.
+var SEND_QUEUE_SIZE int = 1024
+
+// peer is a connected client. Messages to it are queued and written by its
+// own goroutine, so that a slow client never holds up the others.
+type peer struct {
+	Player
+	conn net.Conn
+	// limit is SEND_QUEUE_SIZE when the peer connected.
+	limit int
+
+	mu      sync.Mutex
+	ready   *sync.Cond
+	queue   []Message
+	closing bool
+	failed  bool
+}
+
+func newPeer(conn net.Conn) *peer {
+	p := &peer{conn: conn, limit: SEND_QUEUE_SIZE}
+	p.ready = sync.NewCond(&p.mu)
+	go p.write()
+	return p
+}
+
+// send queues msg without waiting for it to be written.
+func (p *peer) send(msg Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closing || p.failed {
+		return
+	}
+	if len(p.queue) >= p.limit {
+		logger.Warn("co-op client fell behind", "player", p.ID)
+		p.fail()
+		return
+	}
+	p.queue = append(p.queue, msg)
+	p.ready.Signal()
+}
+
+// close writes whatever is still queued, then closes the connection.
+func (p *peer) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closing = true
+	p.ready.Signal()
+}
+
+// fail drops the connection and anything still to be sent. p.mu must be held.
+func (p *peer) fail() {
+	p.failed = true
+	p.queue = nil
+	p.conn.Close()
+	p.ready.Signal()
+}
+
+func (p *peer) write() {
+	defer p.conn.Close()
+	enc := json.NewEncoder(p.conn)
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.closing && !p.failed {
+			p.ready.Wait()
+		}
+		queued := p.queue
+		p.queue = nil
+		done := p.failed || p.closing && len(queued) == 0
+		p.mu.Unlock()
+		if done {
+			return
+		}
+
+		for _, msg := range queued {
+			p.conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
+			if err := enc.Encode(msg); err != nil {
+				logger.Warn("co-op send failed", "player", p.ID, "err", err)
+				p.mu.Lock()
+				p.fail()
+				p.mu.Unlock()
+				return
+			}
+		}
+	}
+}
+
+// Server shares a puzzle with every client that connects to it.
+type Server struct {
+	mode      Mode
+	penalties Penalties
+	listener  net.Listener
+	puzzle    []byte
+	answer    string
+	locked    bool
+	numCols   int
+
+	mu            sync.Mutex
+	state         []byte
+	players       map[int]*peer
+	spectators    map[int]*peer
+	nextID        int
+	started       time.Time
+	elapsedBefore time.Duration
+	solvedIn      time.Duration
+	solved        bool
+	closed        bool
+	standings     map[int]*Standing
+}
+
+// Listen starts sharing puz for co-op, with elapsed already spent on it, at
+// address. Call Serve to accept players.
+func Listen(network, address string, puz *puzzle.PuzzleDefinition, elapsed time.Duration) (*Server, error) {
+	data, err := loader.EncodeJSON(puz, true)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		mode:          Together,
+		listener:      listener,
+		puzzle:        data,
+		answer:        puz.Answer,
+		locked:        puz.Locked,
+		numCols:       puz.NumCols,
+		state:         []byte(puz.CurrentState),
+		players:       make(map[int]*peer),
+		spectators:    make(map[int]*peer),
+		started:       time.Now(),
+		elapsedBefore: elapsed,
+	}, nil
+}
+
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve accepts players until the server is closed.
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// Close stops accepting players and disconnects everyone.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for _, p := range s.players {
+		p.conn.Close()
+	}
+	for _, p := range s.spectators {
+		p.conn.Close()
+	}
+	s.mu.Unlock()
+	return s.listener.Close()
+}
+
+// elapsed is how long the puzzle has been worked on. Racers are each timed
+// from when they join, so their clocks start from nothing.
+func (s *Server) elapsed() time.Duration {
+	if s.mode == Race {
+		return 0
+	}
+	if s.solved {
+		return s.solvedIn
+	}
+	return s.elapsedBefore + time.Since(s.started)
+}
+
+func (s *Server) handle(conn net.Conn) {
+	dec := json.NewDecoder(conn)
+	var hello Message
+	conn.SetReadDeadline(time.Now().Add(HANDSHAKE_TIMEOUT))
+	if err := dec.Decode(&hello); err != nil || hello.Type != Hello {
+		logger.Warn("co-op handshake failed", "remote", conn.RemoteAddr(), "err", err)
+		conn.Close()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	if hello.Spectator {
+		s.spectate(conn, dec, hello)
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	p := newPeer(conn)
+	p.Player = Player{ID: s.nextID, Name: hello.Name}
+	p.send(Message{
+		Type:       Welcome,
+		Mode:       s.mode,
+		Player:     p.ID,
+		Puzzle:     s.puzzle,
+		State:      string(s.state),
+		Elapsed:    s.elapsed(),
+		Players:    s.playerList(),
+		Spectators: len(s.spectators),
+	})
+	if s.mode == Race {
+		s.players[p.ID] = p
+		s.standings[p.ID] = &Standing{Player: p.ID, Name: p.Name, Connected: true, joined: time.Now()}
+		s.broadcast(s.standingsMessage(), 0)
+	} else {
+		s.broadcast(Message{Type: Join, Player: p.ID, Name: p.Name}, p.ID)
+		s.players[p.ID] = p
+	}
+	s.mu.Unlock()
+	logger.Info("co-op player joined", "player", p.ID, "name", p.Name, "remote", conn.RemoteAddr())
+
+	for {
+		var msg Message
+		if err := dec.Decode(&msg); err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logger.Debug("co-op read ended", "player", p.ID, "err", err)
+			}
+			break
+		}
+		s.receive(p, msg)
+	}
+
+	s.mu.Lock()
+	delete(s.players, p.ID)
+	p.close()
+	if s.mode == Race {
+		s.standings[p.ID].Connected = false
+		s.broadcast(s.standingsMessage(), 0)
+	} else {
+		s.broadcast(Message{Type: Leave, Player: p.ID, Name: p.Name}, 0)
+	}
+	s.mu.Unlock()
+	logger.Info("co-op player left", "player", p.ID, "name", p.Name)
+}
+
+// spectate sends a spectator everything players are sent until they leave.
+func (s *Server) spectate(conn net.Conn, dec *json.Decoder, hello Message) {
+	p := newPeer(conn)
+	if s.mode == Race {
+		p.send(Message{Type: Refuse, Name: "races can't be watched"})
+		p.close()
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	p.Player = Player{ID: s.nextID, Name: hello.Name}
+	s.spectators[p.ID] = p
+	p.send(Message{
+		Type:       Welcome,
+		Mode:       s.mode,
+		Player:     p.ID,
+		Puzzle:     s.puzzle,
+		State:      string(s.state),
+		Elapsed:    s.elapsed(),
+		Players:    s.playerList(),
+		Spectator:  true,
+		Spectators: len(s.spectators),
+	})
+	s.broadcast(Message{Type: Audience, Spectators: len(s.spectators)}, p.ID)
+	s.mu.Unlock()
+	logger.Info("co-op spectator joined", "spectator", p.ID, "name", p.Name, "remote", conn.RemoteAddr())
+
+	// Nothing a spectator sends is used, so reading only notices them leave.
+	for {
+		var msg Message
+		if err := dec.Decode(&msg); err != nil {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	delete(s.spectators, p.ID)
+	p.close()
+	s.broadcast(Message{Type: Audience, Spectators: len(s.spectators)}, 0)
+	s.mu.Unlock()
+	logger.Info("co-op spectator left", "spectator", p.ID, "name", p.Name)
+}
+
+func (s *Server) receive(p *peer, msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg.Player = p.ID
+	if s.mode == Race {
+		s.receiveRace(p, msg)
+		return
+	}
+	switch msg.Type {
+	case Edit:
+		i := msg.Row*s.numCols + msg.Col
+		if msg.Row < 0 || msg.Col < 0 || msg.Col >= s.numCols || i >= len(s.state) ||
+			s.state[i] == '.' || len(msg.Fill) != 1 || msg.Fill == "." {
+			return
+		}
+		s.state[i] = msg.Fill[0]
+		// The sender gets its own edit back so that every client applies
+		// edits in the same order.
+		s.broadcast(msg, 0)
+		if !s.locked && !s.solved && string(s.state) == s.answer {
+			s.solvedIn = s.elapsed()
+			s.solved = true
+			s.broadcast(Message{Type: Solved, Elapsed: s.solvedIn}, 0)
+		}
+	case Cursor:
+		p.Row, p.Col, p.Down = msg.Row, msg.Col, msg.Down
+		s.broadcast(msg, p.ID)
+	case Marks:
+		s.broadcast(msg, p.ID)
+	}
+}
+
+// broadcast sends msg to every player and spectator but except.
+func (s *Server) broadcast(msg Message, except int) {
+	for _, peers := range []map[int]*peer{s.players, s.spectators} {
+		for id, p := range peers {
+			if id != except {
+				p.send(msg)
+			}
+		}
+	}
+}
+
+func (s *Server) playerList() []Player {
+	players := make([]Player, 0, len(s.players))
+	for _, p := range s.players {
+		players = append(players, p.Player)
+	}
+	slices.SortFunc(players, func(a, b Player) int { return a.ID - b.ID })
+	return players
+}
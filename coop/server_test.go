@@ -0,0 +1,302 @@
+package coop
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+func testPuzzle() *puzzle.PuzzleDefinition {
+	puz := &puzzle.PuzzleDefinition{
+		Title:        "Test",
+		NumRows:      3,
+		NumCols:      3,
+		Answer:       "CAT" + "ARE" + "TEN",
+		CurrentState: strings.Repeat("-", 9),
+	}
+	puz.AssignClues(make([]string, 18))
+	puz.NumClues = len(puz.AcrossClues) + len(puz.DownClues)
+	return puz
+}
+
+// listen starts a co-op server on a free loopback port.
+func listen(t *testing.T) *Server {
+	t.Helper()
+	s, err := Listen("tcp", "127.0.0.1:0", testPuzzle(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func dialTest(t *testing.T, s *Server, name string) *Client {
+	t.Helper()
+	c, err := Dial("tcp", s.Addr().String(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// next waits for the next message of type want, skipping others.
+func next(t *testing.T, c *Client, want MessageType) Message {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg, ok := <-c.Messages():
+			if !ok {
+				t.Fatalf("connection closed waiting for %s", want)
+			}
+			if msg.Type == want {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+}
+
+func TestConcurrentEditsConverge(t *testing.T) {
+	s := listen(t)
+	a := dialTest(t, s, "alice")
+	b := dialTest(t, s, "bob")
+	if join := next(t, a, Join); join.Player != b.Player || join.Name != "bob" {
+		t.Fatalf("alice was told %+v joined, want bob", join)
+	}
+
+	const edits = 200
+	var wg sync.WaitGroup
+	for _, player := range []struct {
+		client  *Client
+		letters string
+	}{{a, "XYZ"}, {b, "QRS"}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range edits {
+				// Both fight over the first square, and each fills another.
+				row, col := 0, 0
+				if i%2 == 1 {
+					row, col = 1+i%2, i%3
+				}
+				err := player.client.Send(Message{Type: Edit, Row: row, Col: col, Fill: player.letters[i%3 : i%3+1]})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Both are sent every edit, their own included, in the same order.
+	states := make([]string, 2)
+	orders := make([][]Message, 2)
+	for i, c := range []*Client{a, b} {
+		state := []byte(c.Puzzle.CurrentState)
+		for range 2 * edits {
+			edit := next(t, c, Edit)
+			state[edit.Row*3+edit.Col] = edit.Fill[0]
+			orders[i] = append(orders[i], edit)
+		}
+		states[i] = string(state)
+	}
+	if !slices.EqualFunc(orders[0], orders[1], func(x, y Message) bool {
+		return x.Player == y.Player && x.Row == y.Row && x.Col == y.Col && x.Fill == y.Fill
+	}) {
+		t.Error("edits arrived in different orders")
+	}
+	if states[0] != states[1] {
+		t.Errorf("grids differ: %q and %q", states[0], states[1])
+	}
+	s.mu.Lock()
+	serverState := string(s.state)
+	s.mu.Unlock()
+	if states[0] != serverState {
+		t.Errorf("clients have %q, server has %q", states[0], serverState)
+	}
+}
+
+func TestSolved(t *testing.T) {
+	s := listen(t)
+	a := dialTest(t, s, "alice")
+	b := dialTest(t, s, "bob")
+	puz := testPuzzle()
+
+	for i, letter := range puz.Answer {
+		c := a
+		if i%2 == 1 {
+			c = b
+		}
+		if err := c.Send(Message{Type: Edit, Row: i / 3, Col: i % 3, Fill: string(letter)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []*Client{a, b} {
+		if solved := next(t, c, Solved); solved.Elapsed <= 0 {
+			t.Errorf("solved in %v", solved.Elapsed)
+		}
+	}
+
+	// Changing a square after solving doesn't solve it again.
+	a.Send(Message{Type: Edit, Row: 0, Col: 0, Fill: "X"})
+	a.Send(Message{Type: Edit, Row: 0, Col: 0, Fill: "C"})
+	a.Send(Message{Type: Cursor, Row: 2, Col: 2})
+	next(t, b, Cursor)
+	for {
+		select {
+		case msg := <-b.Messages():
+			if msg.Type == Solved {
+				t.Fatal("sent Solved twice")
+			}
+			continue
+		default:
+		}
+		break
+	}
+}
+
+func TestSlowClientDoesNotHoldUpOthers(t *testing.T) {
+	queueSize := SEND_QUEUE_SIZE
+	SEND_QUEUE_SIZE = 16
+	t.Cleanup(func() { SEND_QUEUE_SIZE = queueSize })
+	// Unix sockets buffer less than TCP, so the slow client's fill up sooner.
+	s, err := Listen("unix", filepath.Join(t.TempDir(), "coop.sock"), testPuzzle(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+
+	// A client that joins and then stops reading.
+	slow, err := net.Dial("unix", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Close()
+	if err := json.NewEncoder(slow).Encode(Message{Type: Hello, Name: "slow"}); err != nil {
+		t.Fatal(err)
+	}
+	a, err := Dial("unix", s.Addr().String(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := Dial("unix", s.Addr().String(), "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	// Bob keeps up with every message, while they pile up for the slow
+	// client. Waiting on it would stall everyone for WRITE_TIMEOUT.
+	squares := make([]Square, 200)
+	for range 500 {
+		start := time.Now()
+		if err := a.Send(Message{Type: Marks, Squares: squares}); err != nil {
+			t.Fatal(err)
+		}
+		next(t, b, Marks)
+		if wait := time.Since(start); wait > WRITE_TIMEOUT/2 {
+			t.Fatalf("bob waited %v for a message", wait)
+		}
+	}
+
+	// The slow client was dropped rather than waited on.
+	slow.SetReadDeadline(time.Now().Add(WRITE_TIMEOUT / 2))
+	if _, err := io.Copy(io.Discard, slow); err != nil {
+		t.Errorf("slow client still connected: %v", err)
+	}
+}
+
+// readers counts the goroutines reading for a Client.
+func readers() int {
+	buf := make([]byte, 1<<20)
+	return strings.Count(string(buf[:runtime.Stack(buf, true)]), "coop.(*Client).read(")
+}
+
+func TestCloseStopsReading(t *testing.T) {
+	s := listen(t)
+	a := dialTest(t, s, "alice")
+	b, err := Dial("tcp", s.Addr().String(), "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next(t, a, Join)
+	// Send bob more than he buffers, without reading any of it.
+	for i := range 200 {
+		a.Send(Message{Type: Cursor, Row: i % 3})
+	}
+	time.Sleep(100 * time.Millisecond)
+	before := readers()
+
+	b.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for readers() >= before {
+		if time.Now().After(deadline) {
+			t.Fatal("bob's reader is still running after closing")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSpectatorsOnlyWatch(t *testing.T) {
+	s := listen(t)
+	a := dialTest(t, s, "alice")
+	watcher, err := Spectate("tcp", s.Addr().String(), "watcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { watcher.Close() })
+	if !watcher.Spectator {
+		t.Error("the spectator wasn't welcomed as one")
+	}
+	if audience := next(t, a, Audience); audience.Spectators != 1 {
+		t.Errorf("alice was told %d are watching, want 1", audience.Spectators)
+	}
+
+	// What the spectator sends is ignored, and what players send reaches them.
+	watcher.Send(Message{Type: Edit, Row: 0, Col: 0, Fill: "X"})
+	watcher.Send(Message{Type: Cursor, Row: 2, Col: 2})
+	a.Send(Message{Type: Edit, Row: 1, Col: 1, Fill: "R"})
+	if edit := next(t, watcher, Edit); edit.Player != a.Player || edit.Fill != "R" {
+		t.Errorf("spectator was sent %+v, want alice's edit", edit)
+	}
+	a.Send(Message{Type: Cursor, Row: 1, Col: 2})
+	next(t, watcher, Cursor)
+	s.mu.Lock()
+	state := string(s.state)
+	s.mu.Unlock()
+	if state != "----R----" {
+		t.Errorf("grid is %q after the spectator's edit", state)
+	}
+	for {
+		select {
+		case msg := <-a.Messages():
+			if (msg.Type == Edit || msg.Type == Cursor) && msg.Player != a.Player {
+				t.Errorf("alice was sent %+v from the spectator", msg)
+			}
+			continue
+		default:
+		}
+		break
+	}
+
+	watcher.Close()
+	if audience := next(t, a, Audience); audience.Spectators != 0 {
+		t.Errorf("alice was told %d are watching after the spectator left", audience.Spectators)
+	}
+}
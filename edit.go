@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/solver"
+)
+
+func runEdit(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	var options solverOptions
+	options.registerAppearance(fs)
+	var fill fillOptions
+	fill.register(fs)
+	size := fs.String("size", "15x15", "size of a new grid, as rows x columns")
+	symmetric := fs.Bool("symmetric", true, "mirror blocks to keep the grid in rotational symmetry")
+	title := fs.String("title", "", "set the puzzle's title")
+	author := fs.String("author", "", "set the puzzle's author")
+	copyright := fs.String("copyright", "", "set the puzzle's copyright notice")
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+	path := fs.Arg(0)
+	if !slices.Contains(loader.Formats, strings.ToLower(filepath.Ext(path))) {
+		fmt.Fprintf(os.Stderr, "cruciterm edit: %s must end in one of %s\n", path, strings.Join(loader.Formats, ", "))
+		return exitUsage
+	}
+	var rows, cols int
+	if _, err := fmt.Sscanf(strings.ToLower(*size), "%dx%d", &rows, &cols); err != nil ||
+		rows < 2 || cols < 2 || rows > solver.MAX_GRID_SIZE || cols > solver.MAX_GRID_SIZE {
+		fmt.Fprintf(os.Stderr, "cruciterm edit: -size must be rows x columns from 2x2 to %dx%d\n", solver.MAX_GRID_SIZE, solver.MAX_GRID_SIZE)
+		return exitUsage
+	}
+	if !fill.check(cmd) {
+		return exitUsage
+	}
+
+	cfg, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if err := options.setup(cfg); err != nil {
+		return fail(cmd, err)
+	}
+
+	words, err := fill.load(cfg)
+	if err != nil {
+		return fail(cmd, err)
+	}
+
+	editorOptions := solver.EditorOptions{
+		Path:      path,
+		Symmetric: *symmetric,
+		WordList:  words,
+		Fill:      fill.FillOptions,
+	}
+	var puz puzzle.PuzzleDefinition
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		puz = puzzle.PuzzleDefinition{
+			NumRows: rows,
+			NumCols: cols,
+			Answer:  strings.Repeat("-", rows*cols),
+		}
+		editorOptions.Unsaved = true
+	} else {
+		if puz, err = loadPuzzle(path); err != nil {
+			return fail(cmd, err)
+		}
+		if puz.Locked {
+			return fail(cmd, fmt.Errorf("%s: %w", path, loader.ErrLockedSolution))
+		}
+	}
+	for field, value := range map[*string]string{&puz.Title: *title, &puz.Author: *author, &puz.Copyright: *copyright} {
+		if value != "" && *field != value {
+			*field = value
+			editorOptions.Unsaved = true
+		}
+	}
+
+	if err := solver.RunEditor(puz, editorOptions); err != nil {
+		return fail(cmd, err)
+	}
+	return exitOK
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/config"
+	"github.com/tylerwgrass/cruciterm/logger"
+	"github.com/tylerwgrass/cruciterm/source"
+)
+
+var errNoSources = errors.New("no sources configured; add some to \"sources\" in the config file")
+
+func runFetch(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	dateFlag := fs.String("date", "", "fetch the puzzles published on this day, as YYYY-MM-DD (default today)")
+	days := fs.Int("days", 1, "fetch this many days of puzzles, going back from -date")
+	if code, ok := parseFlags(fs, args, -1); !ok {
+		return code
+	}
+	if *days < 1 {
+		fmt.Fprintln(os.Stderr, "cruciterm fetch: -days must be at least 1")
+		return exitUsage
+	}
+	date := time.Now()
+	if *dateFlag != "" {
+		var err error
+		if date, err = time.ParseInLocation(source.DateFormat, *dateFlag, time.Local); err != nil {
+			fmt.Fprintln(os.Stderr, "cruciterm fetch: -date must look like YYYY-MM-DD")
+			return exitUsage
+		}
+	}
+
+	cfg, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	sources, err := configuredSources(cfg)
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if len(sources) == 0 {
+		return fail(cmd, errNoSources)
+	}
+	if names := fs.Args(); len(names) > 0 {
+		var picked []source.Source
+		for _, name := range names {
+			i := slices.IndexFunc(sources, func(src source.Source) bool { return src.Name() == name })
+			if i < 0 {
+				fmt.Fprintf(os.Stderr, "cruciterm fetch: no source named %q\n", name)
+				return exitUsage
+			}
+			picked = append(picked, sources[i])
+		}
+		sources = picked
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if !fetchPuzzles(ctx, cmd, sources, date.AddDate(0, 0, 1-*days), date, true) {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// configuredSources makes a source for each one in the config.
+func configuredSources(cfg config.Config) ([]source.Source, error) {
+	var sources []source.Source
+	for _, sc := range cfg.Sources {
+		src, err := source.NewHTTP(sc.Name, sc.URL)
+		if err != nil {
+			return nil, fmt.Errorf("source %q: %w", sc.Name, err)
+		}
+		for _, day := range sc.Days {
+			weekday, ok := parseWeekday(day)
+			if !ok {
+				return nil, fmt.Errorf("source %q: unknown day %q", sc.Name, day)
+			}
+			src.Days = append(src.Days, weekday)
+		}
+		if sc.Since != "" {
+			if src.Since, err = time.ParseInLocation(source.DateFormat, sc.Since, time.Local); err != nil {
+				return nil, fmt.Errorf("source %q: since must look like YYYY-MM-DD", sc.Name)
+			}
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// parseWeekday reads a day of the week, in full or by its first three letters.
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if s == name || s == name[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// fetchPuzzles downloads every puzzle the sources published from from to to
+// into the library's cache, reporting on each one when verbose. It returns
+// false if any download failed; days without a puzzle are not failures.
+func fetchPuzzles(ctx context.Context, cmd *command, sources []source.Source, from, to time.Time, verbose bool) bool {
+	cache := source.Cache{Dir: config.SourcesDir()}
+	ok := true
+	for _, src := range sources {
+		dates, err := src.Dates(ctx, from, to)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cruciterm %s: %s: %v\n", cmd.name, src.Name(), err)
+			ok = false
+			continue
+		}
+		for _, date := range dates {
+			path, fetched, err := cache.Get(ctx, src, date)
+			day := date.Format(source.DateFormat)
+			switch {
+			case errors.Is(err, source.ErrNotPublished):
+				logger.Info("no puzzle published", "source", src.Name(), "date", day)
+				if verbose {
+					fmt.Printf("%s %s: no puzzle\n", src.Name(), day)
+				}
+			case err != nil:
+				logger.Warn("could not fetch puzzle", "source", src.Name(), "date", day, "err", err)
+				fmt.Fprintf(os.Stderr, "cruciterm %s: %s %s: %v\n", cmd.name, src.Name(), day, err)
+				ok = false
+				if ctx.Err() != nil {
+					return false
+				}
+			case verbose && fetched:
+				fmt.Printf("%s %s: downloaded %s\n", src.Name(), day, path)
+			case verbose:
+				fmt.Printf("%s %s: already have %s\n", src.Name(), day, path)
+			}
+		}
+	}
+	return ok
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/config"
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/solver"
+	"github.com/tylerwgrass/cruciterm/wordlist"
+)
+
+var errNoWordList = errors.New("no word list to fill from; give one with -words or \"wordList\" in the config file")
+
+// fillOptions are the flags shared by the commands that autofill grids.
+type fillOptions struct {
+	wordsPath string
+	solver.FillOptions
+}
+
+func (f *fillOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.wordsPath, "words", "", "word list to fill from, one WORD;score per line (default from the config)")
+	fs.IntVar(&f.MinScore, "min-score", 0, "leave out words scored lower than this")
+	fs.Float64Var(&f.Randomness, "random", 0, "from 0 to 1, how much to vary fills from the best scored words")
+	fs.Uint64Var(&f.Seed, "seed", 0, "seed for -random, to repeat a fill (default random)")
+	fs.DurationVar(&f.TimeLimit, "time", 30*time.Second, "give up on a fill after this long")
+}
+
+// check reports a usage error for out of range flags.
+func (f fillOptions) check(cmd *command) bool {
+	if f.Randomness < 0 || f.Randomness > 1 {
+		fmt.Fprintf(os.Stderr, "cruciterm %s: -random must be from 0 to 1\n", cmd.name)
+		return false
+	}
+	return true
+}
+
+// load reads the word list, falling back to the config. It returns nil when
+// neither names one.
+func (f fillOptions) load(cfg config.Config) (*wordlist.List, error) {
+	if f.wordsPath == "" {
+		f.wordsPath = cfg.WordList
+	}
+	if f.wordsPath == "" {
+		return nil, nil
+	}
+	return wordlist.Load(f.wordsPath)
+}
+
+func runFill(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	var options fillOptions
+	options.register(fs)
+	output := fs.String("o", "", "output file (default <input>.filled with the input's extension)")
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+	if !options.check(cmd) {
+		return exitUsage
+	}
+
+	cfg, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	words, err := options.load(cfg)
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if words == nil {
+		return fail(cmd, errNoWordList)
+	}
+
+	input := fs.Arg(0)
+	puz, err := loadPuzzle(input)
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if puz.Locked {
+		return fail(cmd, fmt.Errorf("%s: %w", input, loader.ErrLockedSolution))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	answer, err := solver.FillPuzzle(ctx, &puz, words, options.FillOptions)
+	if err != nil {
+		return fail(cmd, fmt.Errorf("%s: %w", input, err))
+	}
+	filled := strings.Count(puz.Answer, "-") - strings.Count(answer, "-")
+	puz.Answer = answer
+	puz.UpdateClueAnswers()
+
+	if *output == "" {
+		ext := filepath.Ext(input)
+		*output = strings.TrimSuffix(input, ext) + ".filled" + ext
+	}
+	if err := loader.SaveFile(*output, &puz); err != nil {
+		return fail(cmd, fmt.Errorf("%s: %w", *output, err))
+	}
+	for row := range puz.NumRows {
+		fmt.Println(answer[row*puz.NumCols : (row+1)*puz.NumCols])
+	}
+	fmt.Printf("filled %d squares, wrote %s\n", filled, *output)
+	return exitOK
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+func runInfo(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	asJSON := fs.Bool("json", false, "print the puzzle as JSON")
+	noAnswers := fs.Bool("no-answers", false, "leave out the solution and clue answers")
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+
+	_, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+
+	puz, err := loadPuzzle(fs.Arg(0))
+	if err != nil {
+		return fail(cmd, err)
+	}
+	includeAnswers := !*noAnswers && !puz.Locked
+
+	if *asJSON {
+		data, err := loader.EncodeJSON(&puz, includeAnswers)
+		if err != nil {
+			return fail(cmd, err)
+		}
+		fmt.Println(string(data))
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Title:\t%s\n", puz.Title)
+	fmt.Fprintf(w, "Author:\t%s\n", puz.Author)
+	fmt.Fprintf(w, "Copyright:\t%s\n", puz.Copyright)
+	fmt.Fprintf(w, "Version:\t%s\n", puz.Version)
+	fmt.Fprintf(w, "Size:\t%dx%d\n", puz.NumCols, puz.NumRows)
+	fmt.Fprintf(w, "Clues:\t%d across, %d down\n", len(puz.AcrossClues), len(puz.DownClues))
+	fmt.Fprintf(w, "Locked:\t%t\n", puz.Locked)
+	if numCircles := countCircles(&puz); numCircles > 0 {
+		fmt.Fprintf(w, "Circles:\t%d\n", numCircles)
+	}
+	if len(puz.Rebus) > 0 {
+		fmt.Fprintf(w, "Rebus squares:\t%d\n", len(puz.Rebus))
+	}
+	if puz.Notes != "" {
+		fmt.Fprintf(w, "Notes:\t%s\n", strings.ReplaceAll(puz.Notes, "\n", " "))
+	}
+	w.Flush()
+
+	printClueTable("ACROSS", &puz, puz.AcrossClues, includeAnswers)
+	printClueTable("DOWN", &puz, puz.DownClues, includeAnswers)
+	return exitOK
+}
+
+func printClueTable(heading string, puz *puzzle.PuzzleDefinition, clues []*puzzle.Clue, includeAnswers bool) {
+	fmt.Printf("\n%s\n", heading)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if includeAnswers {
+		fmt.Fprintln(w, "NUM\tSTART\tEND\tLEN\tANSWER\tCLUE")
+	} else {
+		fmt.Fprintln(w, "NUM\tSTART\tEND\tLEN\tCLUE")
+	}
+	for _, clue := range clues {
+		fmt.Fprintf(w, "%d\t%d,%d\t%d,%d\t%d\t", clue.Num, clue.StartRow, clue.StartCol, clue.EndRow, clue.EndCol, len(clue.Answer))
+		if includeAnswers {
+			fmt.Fprintf(w, "%s\t", loader.FullAnswer(clue, puz.Rebus, puz.NumCols))
+		}
+		fmt.Fprintf(w, "%s\n", clue.Clue)
+	}
+	w.Flush()
+}
+
+func countCircles(puz *puzzle.PuzzleDefinition) int {
+	count := 0
+	for _, circled := range puz.Circles {
+		if circled {
+			count++
+		}
+	}
+	return count
+}
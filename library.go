@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/config"
+	"github.com/tylerwgrass/cruciterm/library"
+	"github.com/tylerwgrass/cruciterm/logger"
+	"github.com/tylerwgrass/cruciterm/solver"
+	"github.com/tylerwgrass/cruciterm/stats"
+)
+
+func runLibrary(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	var options solverOptions
+	options.register(fs)
+	fetch := fs.Bool("fetch", false, "download today's puzzles from the sources in the config before opening")
+	if code, ok := parseFlags(fs, args, -1); !ok {
+		return code
+	}
+
+	cfg, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if err := options.setup(cfg); err != nil {
+		return fail(cmd, err)
+	}
+	options.inLibrary = true
+
+	if *fetch {
+		sources, err := configuredSources(cfg)
+		if err != nil {
+			return fail(cmd, err)
+		}
+		if len(sources) == 0 {
+			return fail(cmd, errNoSources)
+		}
+		// Failed downloads have been reported, but there are still puzzles to
+		// browse.
+		now := time.Now()
+		fetchPuzzles(context.Background(), cmd, sources, now, now, false)
+	}
+
+	return browseLibrary(cmd, libraryDirs(cfg, fs.Args()), options)
+}
+
+// libraryDirs are the directories to find puzzles in: those given, or else
+// the configured ones along with everything downloaded from sources.
+func libraryDirs(cfg config.Config, dirs []string) []string {
+	if len(dirs) > 0 {
+		return dirs
+	}
+	dirs = cfg.PuzzleDirs
+	if len(dirs) == 0 {
+		dirs = config.DefaultPuzzleDirs()
+	}
+	return append(slices.Clone(dirs), config.SourcesDir())
+}
+
+// browseLibrary opens the library of puzzles in dirs, and then whichever
+// puzzles are picked from it.
+func browseLibrary(cmd *command, dirs []string, options solverOptions) int {
+	state := userStateIn(options.stateDir)
+	// Come back to the library after each solve so another puzzle can be
+	// picked, with the statuses brought up to date.
+	for {
+		entries, errs := library.Scan(dirs, state.progress)
+		for _, err := range errs {
+			logger.Warn("library scan", "err", err)
+		}
+		solves, err := state.history.Load()
+		if err != nil {
+			logger.Warn("could not load solve history", "err", err)
+		}
+		opened, err := solver.RunLibrary(entries, stats.Summarize(solves, time.Now()), options.terminal)
+		if err != nil {
+			return fail(cmd, err)
+		}
+		if len(opened) == 0 {
+			return exitOK
+		}
+
+		paths := make([]string, len(opened))
+		for i, entry := range opened {
+			paths[i] = entry.Path
+		}
+		if code := solvePuzzles(cmd, paths, true, options); code != exitOK {
+			return code
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package library finds the puzzles in a set of directories and works out
+// how far along each one is.
+package library
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/logger"
+	"github.com/tylerwgrass/cruciterm/progress"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+type Status int
+
+const (
+	Unstarted Status = iota
+	InProgress
+	Solved
+)
+
+func (s Status) String() string {
+	switch s {
+	case InProgress:
+		return "in progress"
+	case Solved:
+		return "solved"
+	}
+	return "unstarted"
+}
+
+// Entry is a puzzle file in the library.
+type Entry struct {
+	Path    string
+	Title   string
+	Author  string
+	Date    time.Time
+	NumRows int
+	NumCols int
+	Status  Status
+	// PercentFilled is how much of the grid has been filled in.
+	PercentFilled int
+	// Elapsed is the time spent solving so far.
+	Elapsed time.Duration
+}
+
+// Scan lists every puzzle loader can read in dirs and their subdirectories,
+// reading each puzzle's status from store. Missing directories are skipped.
+// Files that fail to load are left out and reported in the returned errors.
+func Scan(dirs []string, store progress.Store) ([]Entry, []error) {
+	var entries []Entry
+	var errs []error
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == dir && errors.Is(err, fs.ErrNotExist) {
+					return fs.SkipDir
+				}
+				errs = append(errs, err)
+				return nil
+			}
+			if d.IsDir() || !slices.Contains(loader.Formats, strings.ToLower(filepath.Ext(path))) {
+				return nil
+			}
+			if abs, err := filepath.Abs(path); err == nil {
+				if seen[abs] {
+					return nil
+				}
+				seen[abs] = true
+			}
+
+			entry, err := newEntry(path, d, store)
+			if err != nil {
+				logger.Warn("skipping puzzle in library", "path", path, "err", err)
+				errs = append(errs, err)
+				return nil
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return entries, errs
+}
+
+func newEntry(path string, d fs.DirEntry, store progress.Store) (Entry, error) {
+	puz, err := loader.LoadFile(path)
+	if err != nil {
+		return Entry{}, &fs.PathError{Op: "load", Path: path, Err: err}
+	}
+	entry := Entry{
+		Path:    path,
+		Title:   puz.Title,
+		Author:  puz.Author,
+		NumRows: puz.NumRows,
+		NumCols: puz.NumCols,
+	}
+	// Puzzles from sources are named after the day they were published,
+	// which matters more than when they happened to be downloaded.
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if date, err := time.ParseInLocation(time.DateOnly, name, time.Local); err == nil {
+		entry.Date = date
+	} else if info, err := d.Info(); err == nil {
+		entry.Date = info.ModTime()
+	}
+
+	state := puz.CurrentState
+	saved, err := store.Load(&puz)
+	if err == nil {
+		state = saved.State
+		entry.Elapsed = saved.Elapsed
+	} else if !errors.Is(err, progress.ErrNoProgress) {
+		return Entry{}, err
+	}
+	entry.PercentFilled = percentFilled(state)
+	switch {
+	case err == nil && saved.Solved || state == puz.Answer && !puz.Locked:
+		entry.Status = Solved
+	case entry.PercentFilled > 0:
+		entry.Status = InProgress
+	}
+	return entry, nil
+}
+
+// Load reads the puzzle the entry describes.
+func (e Entry) Load() (puzzle.PuzzleDefinition, error) {
+	return loader.LoadFile(e.Path)
+}
+
+func percentFilled(state string) int {
+	filled, total := 0, 0
+	for _, square := range state {
+		if square == '.' {
+			continue
+		}
+		total++
+		if square != '-' {
+			filled++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return filled * 100 / total
+}
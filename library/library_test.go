@@ -0,0 +1,92 @@
+package library
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/progress"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+func TestScanDates(t *testing.T) {
+	dir := t.TempDir()
+	puz := &puzzle.PuzzleDefinition{
+		Title:        "Dated",
+		NumRows:      2,
+		NumCols:      2,
+		Answer:       "ABCD",
+		CurrentState: "----",
+	}
+	puz.AssignClues([]string{"1A", "3A", "1D", "2D"})
+	puz.NumClues = len(puz.AcrossClues) + len(puz.DownClues)
+	downloaded := time.Date(2025, time.January, 2, 15, 4, 5, 0, time.Local)
+	for _, name := range []string{"2024-03-05.json", "crossword.json", "2024-13-40.json"} {
+		path := filepath.Join(dir, name)
+		if err := loader.SaveFile(path, puz); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, downloaded, downloaded); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, errs := Scan([]string{dir}, progress.Store{Dir: t.TempDir()})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	want := map[string]time.Time{
+		// Named for the day it was published, as sources name their puzzles.
+		"2024-03-05.json": time.Date(2024, time.March, 5, 0, 0, 0, 0, time.Local),
+		"crossword.json":  downloaded,
+		"2024-13-40.json": downloaded,
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("found %d puzzles, want %d", len(entries), len(want))
+	}
+	for _, entry := range entries {
+		name := filepath.Base(entry.Path)
+		if !entry.Date.Equal(want[name]) {
+			t.Errorf("%s dated %v, want %v", name, entry.Date, want[name])
+		}
+	}
+}
+
+func TestScanSkipsBadPuzzles(t *testing.T) {
+	dir := t.TempDir()
+	puz := &puzzle.PuzzleDefinition{
+		Title:        "Good",
+		NumRows:      2,
+		NumCols:      2,
+		Answer:       "ABCD",
+		CurrentState: "----",
+	}
+	puz.AssignClues([]string{"1A", "3A", "1D", "2D"})
+	puz.NumClues = len(puz.AcrossClues) + len(puz.DownClues)
+	for _, name := range []string{"good.puz", "bad.puz"} {
+		if err := loader.SaveFile(filepath.Join(dir, name), puz); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Claim fewer clues than the grid has entries.
+	bad := filepath.Join(dir, "bad.puz")
+	data, err := os.ReadFile(bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[0x2E] = 1
+	if err := os.WriteFile(bad, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, errs := Scan([]string{dir}, progress.Store{Dir: t.TempDir()})
+	if len(entries) != 1 || filepath.Base(entries[0].Path) != "good.puz" {
+		t.Errorf("found %v, want only good.puz", entries)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], loader.ErrFileParse) {
+		t.Errorf("errors = %v, want bad.puz failing to parse", errs)
+	}
+}
@@ -0,0 +1,15 @@
+package loader
+
+// checksumRegion implements the rolling checksum used throughout the .puz
+// format, continuing from an existing checksum.
+func checksumRegion(data []byte, checksum uint16) uint16 {
+	for _, b := range data {
+		if checksum&0x0001 != 0 {
+			checksum = (checksum >> 1) | 0x8000
+		} else {
+			checksum = checksum >> 1
+		}
+		checksum += uint16(b)
+	}
+	return checksum
+}
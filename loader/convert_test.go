@@ -0,0 +1,108 @@
+package loader
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+// comparePuzzles reports every difference between got and want, skipping the
+// fill progress when the format it went through can't hold it.
+func comparePuzzles(t *testing.T, got, want puzzle.PuzzleDefinition, withState bool) {
+	t.Helper()
+	for _, field := range []struct{ name, got, want string }{
+		{"title", got.Title, want.Title},
+		{"author", got.Author, want.Author},
+		{"copyright", got.Copyright, want.Copyright},
+		{"notes", got.Notes, want.Notes},
+		{"answer", got.Answer, want.Answer},
+	} {
+		if field.got != field.want {
+			t.Errorf("%s = %q, want %q", field.name, field.got, field.want)
+		}
+	}
+	if got.NumRows != want.NumRows || got.NumCols != want.NumCols {
+		t.Errorf("size = %dx%d, want %dx%d", got.NumRows, got.NumCols, want.NumRows, want.NumCols)
+	}
+	if withState && got.CurrentState != want.CurrentState {
+		t.Errorf("state = %q, want %q", got.CurrentState, want.CurrentState)
+	}
+	if !slices.Equal(got.Circles, want.Circles) {
+		t.Errorf("circles = %v, want %v", got.Circles, want.Circles)
+	}
+	if !maps.Equal(got.Rebus, want.Rebus) {
+		t.Errorf("rebus = %v, want %v", got.Rebus, want.Rebus)
+	}
+	if gotClues, wantClues := clueTexts(got), clueTexts(want); !slices.Equal(gotClues, wantClues) {
+		t.Errorf("clues = %q, want %q", gotClues, wantClues)
+	}
+}
+
+func clueTexts(puz puzzle.PuzzleDefinition) []string {
+	var texts []string
+	for _, clue := range slices.Concat(puz.AcrossClues, puz.DownClues) {
+		texts = append(texts, fmt.Sprintf("%d %s: %s", clue.Num, clue.Direction, clue.Clue))
+	}
+	return texts
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			want := testPuzzle()
+			want.CurrentState = "TA-S" + "-R-." + "----"
+			path := filepath.Join(t.TempDir(), "puzzle"+format)
+			if err := SaveFile(path, &want); err != nil {
+				t.Fatalf("SaveFile: %v", err)
+			}
+			got, err := LoadFile(path)
+			if err != nil {
+				t.Fatalf("LoadFile: %v", err)
+			}
+			comparePuzzles(t, got, want, !slices.Contains(LossyFields(path, &want), "fill progress"))
+		})
+	}
+}
+
+func TestConvertThroughEveryFormat(t *testing.T) {
+	want := testPuzzle()
+	puz := want
+	dir := t.TempDir()
+	for _, format := range slices.Concat(Formats, Formats) {
+		path := filepath.Join(dir, "puzzle"+format)
+		if err := SaveFile(path, &puz); err != nil {
+			t.Fatalf("SaveFile %s: %v", format, err)
+		}
+		var err error
+		if puz, err = LoadFile(path); err != nil {
+			t.Fatalf("LoadFile %s: %v", format, err)
+		}
+	}
+	comparePuzzles(t, puz, want, true)
+}
+
+func TestLossyFields(t *testing.T) {
+	puz := testPuzzle()
+	puz.Circles[0] = true
+	puz.CurrentState = "T---" + "---." + "----"
+	puz.Notes = "Ünïcode ✓"
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{".puz", []string{"characters outside Latin-1"}},
+		{".ipuz", nil},
+		{".xd", []string{"fill progress", "circles on rebus squares"}},
+		{".json", nil},
+	}
+	for _, test := range tests {
+		if got := LossyFields("puzzle"+test.format, &puz); !slices.Equal(got, test.want) {
+			t.Errorf("LossyFields(%s) = %q, want %q", test.format, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+// ipuz file definition: https://www.puzzazz.com/ipuz/v2
+type ipuzPuzzle struct {
+	Version    string                `json:"version"`
+	Kind       []string              `json:"kind"`
+	Title      string                `json:"title,omitempty"`
+	Author     string                `json:"author,omitempty"`
+	Copyright  string                `json:"copyright,omitempty"`
+	Notes      string                `json:"notes,omitempty"`
+	Block      string                `json:"block,omitempty"`
+	Dimensions ipuzDimensions        `json:"dimensions"`
+	Puzzle     [][]json.RawMessage   `json:"puzzle"`
+	Solution   [][]json.RawMessage   `json:"solution,omitempty"`
+	Saved      [][]json.RawMessage   `json:"saved,omitempty"`
+	Clues      map[string][]ipuzClue `json:"clues"`
+}
+
+type ipuzDimensions struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type ipuzCell struct {
+	Cell  json.RawMessage `json:"cell,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+	Style *ipuzStyle      `json:"style,omitempty"`
+}
+
+type ipuzStyle struct {
+	Shapebg string `json:"shapebg,omitempty"`
+}
+
+// ipuzClue is a clue given either as a [number, text] pair or an object.
+type ipuzClue struct {
+	Number int
+	Clue   string
+}
+
+const ipuzVersion = "http://ipuz.org/v2"
+const ipuzCrosswordKind = "http://ipuz.org/crossword#1"
+const ipuzBlock = "#"
+
+func (c ipuzClue) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{c.Number, c.Clue})
+}
+
+func (c *ipuzClue) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err == nil {
+		if len(pair) < 2 {
+			return ErrFileParse
+		}
+		if c.Number, err = ipuzNumber(pair[0]); err != nil {
+			return err
+		}
+		return json.Unmarshal(pair[1], &c.Clue)
+	}
+	var obj struct {
+		Number json.RawMessage `json:"number"`
+		Clue   string          `json:"clue"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	number, err := ipuzNumber(obj.Number)
+	c.Number, c.Clue = number, obj.Clue
+	return err
+}
+
+func ipuzNumber(data json.RawMessage) (int, error) {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		return n, nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(s)
+}
+
+// ipuzString reads a cell that holds a string, a number or an object with a
+// value (for solutions) or cell (for the puzzle grid). Null reads as "".
+func ipuzString(data json.RawMessage) (string, *ipuzStyle) {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return s, nil
+	}
+	var n json.Number
+	if json.Unmarshal(data, &n) == nil {
+		return n.String(), nil
+	}
+	var cell ipuzCell
+	if json.Unmarshal(data, &cell) == nil {
+		value := cell.Value
+		if value == nil {
+			value = cell.Cell
+		}
+		if value == nil {
+			return "", cell.Style
+		}
+		s, _ := ipuzString(value)
+		return s, cell.Style
+	}
+	return "", nil
+}
+
+func decodeIpuz(data []byte) (puzzle.PuzzleDefinition, error) {
+	var doc ipuzPuzzle
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return puzzle.PuzzleDefinition{}, ErrFileParse
+	}
+	if !isIpuzCrossword(doc.Kind) {
+		return puzzle.PuzzleDefinition{}, ErrFileNotSupported
+	}
+	if doc.Solution == nil {
+		return puzzle.PuzzleDefinition{}, ErrMissingSolution
+	}
+	block := doc.Block
+	if block == "" {
+		block = ipuzBlock
+	}
+
+	puz := puzzle.PuzzleDefinition{
+		Title:     doc.Title,
+		Author:    doc.Author,
+		Copyright: doc.Copyright,
+		Notes:     doc.Notes,
+		NumCols:   doc.Dimensions.Width,
+		NumRows:   doc.Dimensions.Height,
+	}
+	if puz.NumCols == 0 || puz.NumRows == 0 || len(doc.Solution) != puz.NumRows || len(doc.Puzzle) != puz.NumRows {
+		return puzzle.PuzzleDefinition{}, ErrFileParse
+	}
+
+	numCells := puz.NumCols * puz.NumRows
+	answer := make([]byte, 0, numCells)
+	state := make([]byte, 0, numCells)
+	for row := range puz.NumRows {
+		if len(doc.Solution[row]) != puz.NumCols || len(doc.Puzzle[row]) != puz.NumCols {
+			return puzzle.PuzzleDefinition{}, ErrFileParse
+		}
+		for col := range puz.NumCols {
+			i := row*puz.NumCols + col
+			cell, style := ipuzString(doc.Puzzle[row][col])
+			solution, _ := ipuzString(doc.Solution[row][col])
+			if cell == block || string(doc.Puzzle[row][col]) == "null" || solution == block {
+				answer = append(answer, '.')
+				state = append(state, '.')
+				continue
+			}
+			solution = strings.ToUpper(solution)
+			if solution == "" {
+				return puzzle.PuzzleDefinition{}, ErrMissingSolution
+			}
+			if len(solution) > 1 {
+				if puz.Rebus == nil {
+					puz.Rebus = make(map[int]string)
+				}
+				puz.Rebus[i] = solution
+			}
+			answer = append(answer, squareLetter(solution))
+			if style != nil && style.Shapebg == "circle" {
+				if puz.Circles == nil {
+					puz.Circles = make([]bool, numCells)
+				}
+				puz.Circles[i] = true
+			}
+
+			fill := '-'
+			if row < len(doc.Saved) && col < len(doc.Saved[row]) {
+				if saved, _ := ipuzString(doc.Saved[row][col]); saved != "" && saved != "0" {
+					fill = rune(squareLetter(strings.ToUpper(saved)))
+				}
+			}
+			state = append(state, byte(fill))
+		}
+	}
+	puz.Answer = string(answer)
+	puz.CurrentState = string(state)
+
+	across := make(map[int]string)
+	down := make(map[int]string)
+	for direction, clues := range doc.Clues {
+		var texts map[int]string
+		switch {
+		case strings.EqualFold(direction, "across"):
+			texts = across
+		case strings.EqualFold(direction, "down"):
+			texts = down
+		default:
+			continue
+		}
+		for _, clue := range clues {
+			texts[clue.Number] = clue.Clue
+		}
+	}
+	puz.AssignNumberedClues(across, down)
+	if !hasEntries(&puz) {
+		return puzzle.PuzzleDefinition{}, ErrFileParse
+	}
+	return puz, nil
+}
+
+func isIpuzCrossword(kinds []string) bool {
+	for _, kind := range kinds {
+		if strings.HasPrefix(kind, "http://ipuz.org/crossword") {
+			return true
+		}
+	}
+	return false
+}
+
+// squareLetter is the single letter stored in Answer for a square whose full
+// answer may be longer or outside ASCII.
+func squareLetter(answer string) byte {
+	for _, r := range answer {
+		if r < 0x80 {
+			return byte(r)
+		}
+	}
+	return 'X'
+}
+
+func saveIpuzFile(path string, puz *puzzle.PuzzleDefinition) error {
+	if puz.Locked {
+		return ErrLockedSolution
+	}
+
+	numbers := make(map[int]int)
+	for _, clue := range slices.Concat(puz.AcrossClues, puz.DownClues) {
+		numbers[clue.StartRow*puz.NumCols+clue.StartCol] = clue.Num
+	}
+
+	doc := ipuzPuzzle{
+		Version:    ipuzVersion,
+		Kind:       []string{ipuzCrosswordKind},
+		Title:      puz.Title,
+		Author:     puz.Author,
+		Copyright:  puz.Copyright,
+		Notes:      puz.Notes,
+		Dimensions: ipuzDimensions{Width: puz.NumCols, Height: puz.NumRows},
+		Clues:      map[string][]ipuzClue{"Across": ipuzClues(puz.AcrossClues), "Down": ipuzClues(puz.DownClues)},
+	}
+	for row := range puz.NumRows {
+		var puzzleRow, solutionRow, savedRow []json.RawMessage
+		for col := range puz.NumCols {
+			i := row*puz.NumCols + col
+			if puz.Answer[i] == '.' {
+				block := mustJSON(ipuzBlock)
+				puzzleRow = append(puzzleRow, block)
+				solutionRow = append(solutionRow, block)
+				savedRow = append(savedRow, block)
+				continue
+			}
+
+			var cell any = numbers[i]
+			if i < len(puz.Circles) && puz.Circles[i] {
+				cell = ipuzCell{Cell: mustJSON(numbers[i]), Style: &ipuzStyle{Shapebg: "circle"}}
+			}
+			puzzleRow = append(puzzleRow, mustJSON(cell))
+
+			solution := string(puz.Answer[i])
+			if full, ok := puz.Rebus[i]; ok {
+				solution = full
+			}
+			solutionRow = append(solutionRow, mustJSON(solution))
+
+			saved := ""
+			if puz.CurrentState[i] != '-' {
+				saved = string(puz.CurrentState[i])
+			}
+			savedRow = append(savedRow, mustJSON(saved))
+		}
+		doc.Puzzle = append(doc.Puzzle, puzzleRow)
+		doc.Solution = append(doc.Solution, solutionRow)
+		doc.Saved = append(doc.Saved, savedRow)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func ipuzClues(clues []*puzzle.Clue) []ipuzClue {
+	encoded := make([]ipuzClue, len(clues))
+	for i, clue := range clues {
+		encoded[i] = ipuzClue{Number: clue.Num, Clue: clue.Clue}
+	}
+	return encoded
+}
+
+func mustJSON(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("encoding %v: %v", v, err))
+	}
+	return data
+}
@@ -0,0 +1,220 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+// jsonPuzzle is cruciterm's own JSON representation of a puzzle. Grids are
+// stored one string per row using '.' for black squares and '-' for empty
+// squares, as in the .puz format.
+type jsonPuzzle struct {
+	Title     string       `json:"title"`
+	Author    string       `json:"author"`
+	Copyright string       `json:"copyright"`
+	Version   string       `json:"version,omitempty"`
+	Notes     string       `json:"notes,omitempty"`
+	Width     int          `json:"width"`
+	Height    int          `json:"height"`
+	Locked    bool         `json:"locked,omitempty"`
+	Solution  []string     `json:"solution,omitempty"`
+	Fill      []string     `json:"fill"`
+	Across    []jsonClue   `json:"across"`
+	Down      []jsonClue   `json:"down"`
+	Circles   []jsonSquare `json:"circles,omitempty"`
+	Rebus     []jsonSquare `json:"rebus,omitempty"`
+}
+
+type jsonClue struct {
+	Number     int      `json:"number"`
+	StartRow   int      `json:"startRow"`
+	StartCol   int      `json:"startCol"`
+	EndRow     int      `json:"endRow"`
+	EndCol     int      `json:"endCol"`
+	Length     int      `json:"length"`
+	Clue       string   `json:"clue"`
+	Answer     string   `json:"answer,omitempty"`
+	References []string `json:"references,omitempty"`
+}
+
+type jsonSquare struct {
+	Row    int    `json:"row"`
+	Col    int    `json:"col"`
+	Answer string `json:"answer,omitempty"`
+}
+
+// EncodeJSON renders puz as indented JSON. Without includeAnswers the
+// solution, clue answers and rebus answers are left out.
+func EncodeJSON(puz *puzzle.PuzzleDefinition, includeAnswers bool) ([]byte, error) {
+	doc := jsonPuzzle{
+		Title:     puz.Title,
+		Author:    puz.Author,
+		Copyright: puz.Copyright,
+		Version:   puz.Version,
+		Notes:     puz.Notes,
+		Width:     puz.NumCols,
+		Height:    puz.NumRows,
+		Locked:    puz.Locked,
+		Fill:      gridRows(puz.CurrentState, puz.NumCols),
+		Across:    jsonClues(puz.AcrossClues, puz.Rebus, puz.NumCols, includeAnswers),
+		Down:      jsonClues(puz.DownClues, puz.Rebus, puz.NumCols, includeAnswers),
+	}
+	if includeAnswers {
+		doc.Solution = gridRows(puz.Answer, puz.NumCols)
+	}
+	for i, circled := range puz.Circles {
+		if circled {
+			doc.Circles = append(doc.Circles, jsonSquare{Row: i / puz.NumCols, Col: i % puz.NumCols})
+		}
+	}
+	for _, i := range slices.Sorted(maps.Keys(puz.Rebus)) {
+		square := jsonSquare{Row: i / puz.NumCols, Col: i % puz.NumCols}
+		if includeAnswers {
+			square.Answer = puz.Rebus[i]
+		}
+		doc.Rebus = append(doc.Rebus, square)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func jsonClues(clues []*puzzle.Clue, rebus map[int]string, numCols int, includeAnswers bool) []jsonClue {
+	encoded := make([]jsonClue, len(clues))
+	for i, clue := range clues {
+		encoded[i] = jsonClue{
+			Number:   clue.Num,
+			StartRow: clue.StartRow,
+			StartCol: clue.StartCol,
+			EndRow:   clue.EndRow,
+			EndCol:   clue.EndCol,
+			Length:   len(clue.Answer),
+			Clue:     clue.Clue,
+		}
+		if includeAnswers {
+			encoded[i].Answer = FullAnswer(clue, rebus, numCols)
+		}
+		for _, ref := range clue.References {
+			encoded[i].References = append(encoded[i].References, fmt.Sprintf("%d%c", ref.Num, ref.Direction.String()[0]))
+		}
+	}
+	return encoded
+}
+
+// FullAnswer spells out the answer to clue, expanding any rebus squares.
+func FullAnswer(clue *puzzle.Clue, rebus map[int]string, numCols int) string {
+	if len(rebus) == 0 {
+		return clue.Answer
+	}
+	var answer []byte
+	i := 0
+	for row := clue.StartRow; row <= clue.EndRow; row++ {
+		for col := clue.StartCol; col <= clue.EndCol; col++ {
+			if full, ok := rebus[row*numCols+col]; ok {
+				answer = append(answer, full...)
+			} else {
+				answer = append(answer, clue.Answer[i])
+			}
+			i++
+		}
+	}
+	return string(answer)
+}
+
+func gridRows(grid string, numCols int) []string {
+	rows := make([]string, 0, len(grid)/max(numCols, 1))
+	for i := 0; i+numCols <= len(grid); i += numCols {
+		rows = append(rows, grid[i:i+numCols])
+	}
+	return rows
+}
+
+func saveJSONFile(path string, puz *puzzle.PuzzleDefinition) error {
+	data, err := EncodeJSON(puz, true)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// DecodeJSON reads a puzzle written by EncodeJSON with its answers.
+func DecodeJSON(data []byte) (puzzle.PuzzleDefinition, error) {
+	var doc jsonPuzzle
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return puzzle.PuzzleDefinition{}, ErrFileParse
+	}
+	if doc.Solution == nil {
+		return puzzle.PuzzleDefinition{}, ErrMissingSolution
+	}
+
+	puz := puzzle.PuzzleDefinition{
+		Title:        doc.Title,
+		Author:       doc.Author,
+		Copyright:    doc.Copyright,
+		Version:      doc.Version,
+		Notes:        doc.Notes,
+		NumCols:      doc.Width,
+		NumRows:      doc.Height,
+		Locked:       doc.Locked,
+		Answer:       strings.Join(doc.Solution, ""),
+		CurrentState: strings.Join(doc.Fill, ""),
+	}
+	numCells := puz.NumCols * puz.NumRows
+	if numCells == 0 || len(puz.Answer) != numCells {
+		return puzzle.PuzzleDefinition{}, ErrFileParse
+	}
+	if len(puz.CurrentState) != numCells {
+		puz.CurrentState = emptyState(puz.Answer)
+	}
+	for _, square := range doc.Circles {
+		if !inGrid(&puz, square.Row, square.Col) {
+			return puzzle.PuzzleDefinition{}, ErrFileParse
+		}
+		if puz.Circles == nil {
+			puz.Circles = make([]bool, numCells)
+		}
+		puz.Circles[square.Row*puz.NumCols+square.Col] = true
+	}
+	for _, square := range doc.Rebus {
+		if !inGrid(&puz, square.Row, square.Col) {
+			return puzzle.PuzzleDefinition{}, ErrFileParse
+		}
+		if puz.Rebus == nil {
+			puz.Rebus = make(map[int]string)
+		}
+		puz.Rebus[square.Row*puz.NumCols+square.Col] = square.Answer
+	}
+
+	across := make(map[int]string)
+	for _, clue := range doc.Across {
+		across[clue.Number] = clue.Clue
+	}
+	down := make(map[int]string)
+	for _, clue := range doc.Down {
+		down[clue.Number] = clue.Clue
+	}
+	puz.AssignNumberedClues(across, down)
+	if !hasEntries(&puz) {
+		return puzzle.PuzzleDefinition{}, ErrFileParse
+	}
+	return puz, nil
+}
+
+// emptyState is the fill of an untouched grid with the given solution.
+func emptyState(answer string) string {
+	state := []byte(answer)
+	for i := range state {
+		if state[i] != '.' {
+			state[i] = '-'
+		}
+	}
+	return string(state)
+}
+
+func inGrid(puz *puzzle.PuzzleDefinition, row, col int) bool {
+	return row >= 0 && row < puz.NumRows && col >= 0 && col < puz.NumCols
+}
@@ -2,37 +2,115 @@ package loader
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/tylerwgrass/cruciterm/logger"
 	"github.com/tylerwgrass/cruciterm/puzzle"
 
 	"golang.org/x/text/encoding/charmap"
-	"golang.org/x/text/transform"
 )
 
 var ErrFileParse = fmt.Errorf("could not parse file")
 var ErrFileNotSupported = fmt.Errorf("file type not supported")
+var ErrMissingSolution = fmt.Errorf("puzzle has no solution")
+var ErrLockedSolution = fmt.Errorf("solution is locked; unlock the puzzle first")
+
+// Formats lists the file extensions LoadFile and SaveFile understand.
+var Formats = []string{".puz", ".ipuz", ".xd", ".json"}
 
 func LoadFile(path string) (puzzle.PuzzleDefinition, error) {
-	var ext = filepath.Ext(path)
-	if ext == ".puz" {
-		return loadPuzFile(path)
+	format := filepath.Ext(path)
+	if !slices.Contains(Formats, strings.ToLower(format)) {
+		return puzzle.PuzzleDefinition{}, ErrFileNotSupported
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return puzzle.PuzzleDefinition{}, err
+	}
+	return Decode(data, format)
+}
+
+// Decode reads a puzzle that isn't in a file, such as one just downloaded.
+// format is one of Formats.
+func Decode(data []byte, format string) (puzzle.PuzzleDefinition, error) {
+	switch strings.ToLower(format) {
+	case ".puz":
+		return decodePuz(data)
+	case ".ipuz":
+		return decodeIpuz(data)
+	case ".xd":
+		return decodeXd(data)
+	case ".json":
+		return DecodeJSON(data)
 	}
 	return puzzle.PuzzleDefinition{}, ErrFileNotSupported
 }
 
-// .puz file definition: https://code.google.com/archive/p/puz/wikis/FileFormat.wiki
-func loadPuzFile(path string) (puzzle.PuzzleDefinition, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return puzzle.PuzzleDefinition{}, err
+func SaveFile(path string, puz *puzzle.PuzzleDefinition) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".puz":
+		return SavePuzFile(path, puz)
+	case ".ipuz":
+		return saveIpuzFile(path, puz)
+	case ".xd":
+		return saveXdFile(path, puz)
+	case ".json":
+		return saveJSONFile(path, puz)
+	}
+	return ErrFileNotSupported
+}
+
+// LossyFields describes what of puz would be lost by saving it to path, for
+// warning about conversions to formats that cannot hold everything.
+func LossyFields(path string, puz *puzzle.PuzzleDefinition) []string {
+	var lost []string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".puz":
+		text := []string{puz.Title, puz.Author, puz.Copyright, puz.Notes}
+		for _, clue := range slices.Concat(puz.AcrossClues, puz.DownClues) {
+			text = append(text, clue.Clue)
+		}
+		for _, answer := range puz.Rebus {
+			text = append(text, answer)
+		}
+		if slices.ContainsFunc(text, func(s string) bool { return !isLatin1(s) }) {
+			lost = append(lost, "characters outside Latin-1")
+		}
+	case ".xd":
+		if strings.ContainsFunc(puz.CurrentState, func(r rune) bool { return r != '-' && r != '.' }) {
+			lost = append(lost, "fill progress")
+		}
+		for i := range puz.Rebus {
+			if i < len(puz.Circles) && puz.Circles[i] {
+				lost = append(lost, "circles on rebus squares")
+				break
+			}
+		}
 	}
-	defer file.Close()
+	return lost
+}
+
+// hasEntries reports whether puz has entries both across and down, as there
+// is nothing to solve otherwise.
+func hasEntries(puz *puzzle.PuzzleDefinition) bool {
+	return len(puz.AcrossClues) > 0 && len(puz.DownClues) > 0
+}
+
+func isLatin1(s string) bool {
+	return !strings.ContainsFunc(s, func(r rune) bool { return r > 0xFF })
+}
 
+// .puz file definition: https://code.google.com/archive/p/puz/wikis/FileFormat.wiki
+func decodePuz(data []byte) (puzzle.PuzzleDefinition, error) {
+	file := bytes.NewReader(data)
 	puz := puzzle.PuzzleDefinition{}
 	if err := parseHeader(&puz, file); err != nil {
 		return puzzle.PuzzleDefinition{}, ErrFileParse
@@ -40,13 +118,18 @@ func loadPuzFile(path string) (puzzle.PuzzleDefinition, error) {
 	if err := parseState(&puz, file); err != nil {
 		return puzzle.PuzzleDefinition{}, ErrFileParse
 	}
-	if err = parseContent(&puz, file); err != nil {
+	// The header's clue count decides how many strings are read as clues,
+	// so it has to match the grid for them to be assigned.
+	if across, down := puz.CountEntries(); across+down != puz.NumClues {
+		return puzzle.PuzzleDefinition{}, ErrFileParse
+	}
+	if err := parseContent(&puz, file); err != nil || !hasEntries(&puz) {
 		return puzzle.PuzzleDefinition{}, ErrFileParse
 	}
 	return puz, nil
 }
 
-func parseHeader(puz *puzzle.PuzzleDefinition, file *os.File) error {
+func parseHeader(puz *puzzle.PuzzleDefinition, file io.ReadSeeker) error {
 	if _, err := file.Seek(0x18, io.SeekStart); err != nil {
 		return err
 	}
@@ -55,7 +138,17 @@ func parseHeader(puz *puzzle.PuzzleDefinition, file *os.File) error {
 	if _, err := file.Read(ver); err != nil {
 		return err
 	}
-	puz.Version = string(ver)
+	puz.Version = strings.TrimRight(string(ver), "\x00")
+
+	if _, err := file.Seek(0x1E, io.SeekStart); err != nil {
+		return err
+	}
+
+	scrambledChecksum := make([]byte, 0x2)
+	if _, err := file.Read(scrambledChecksum); err != nil {
+		return err
+	}
+	puz.LockChecksum = binary.LittleEndian.Uint16(scrambledChecksum)
 
 	if _, err := file.Seek(0x2C, io.SeekStart); err != nil {
 		return err
@@ -69,10 +162,20 @@ func parseHeader(puz *puzzle.PuzzleDefinition, file *os.File) error {
 	puz.NumCols = int(dimensions[0])
 	puz.NumRows = int(dimensions[1])
 	puz.NumClues = int(binary.LittleEndian.Uint16(dimensions[2:]))
+
+	if _, err := file.Seek(0x32, io.SeekStart); err != nil {
+		return err
+	}
+
+	scrambledTag := make([]byte, 0x2)
+	if _, err := file.Read(scrambledTag); err != nil {
+		return err
+	}
+	puz.Locked = binary.LittleEndian.Uint16(scrambledTag) != 0
 	return nil
 }
 
-func parseState(puz *puzzle.PuzzleDefinition, file *os.File) error {
+func parseState(puz *puzzle.PuzzleDefinition, file io.ReadSeeker) error {
 	if _, err := file.Seek(0x34, io.SeekStart); err != nil {
 		return err
 	}
@@ -86,9 +189,9 @@ func parseState(puz *puzzle.PuzzleDefinition, file *os.File) error {
 	return nil
 }
 
-func parseContent(puz *puzzle.PuzzleDefinition, file *os.File) error {
-	decoder := transform.NewReader(file, charmap.ISO8859_1.NewDecoder())
-	reader := bufio.NewReader(decoder)
+func parseContent(puz *puzzle.PuzzleDefinition, file io.ReadSeeker) error {
+	decoder := charmap.ISO8859_1.NewDecoder()
+	reader := bufio.NewReader(file)
 	delim := byte(0)
 	content := make([]string, puz.NumClues+4)
 	index := 0
@@ -100,7 +203,11 @@ func parseContent(puz *puzzle.PuzzleDefinition, file *os.File) error {
 			}
 			return err
 		}
-		content[index] = string(contentBytes[:len(contentBytes)-1])
+		decoded, err := decoder.Bytes(contentBytes[:len(contentBytes)-1])
+		if err != nil {
+			return err
+		}
+		content[index] = string(decoded)
 		index++
 	}
 
@@ -109,5 +216,85 @@ func parseContent(puz *puzzle.PuzzleDefinition, file *os.File) error {
 	puz.Copyright = content[2]
 	puz.Notes = content[len(content)-1]
 	puz.AssignClues(content[3 : len(content)-1])
+	parseExtensions(puz, reader)
 	return nil
 }
+
+// parseExtensions reads the optional sections that follow the notes. Each
+// has a four letter name, a length, a checksum, the data and a null byte.
+// Sections cruciterm does not use are skipped, and so are malformed ones, as
+// the puzzle can be solved without them.
+func parseExtensions(puz *puzzle.PuzzleDefinition, reader *bufio.Reader) {
+	numCells := puz.NumCols * puz.NumRows
+	var rebusSquares []byte
+	var rebusTable string
+	for {
+		header := make([]byte, 8)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			break
+		}
+		name := string(header[:4])
+		length := int(binary.LittleEndian.Uint16(header[4:6]))
+		data := make([]byte, length+1)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			logger.Warn("skipping truncated puz section", "section", name, "length", length)
+			break
+		}
+		data = data[:length]
+
+		switch name {
+		case "GEXT":
+			if length != numCells {
+				logger.Warn("skipping puz section of the wrong size", "section", name, "length", length, "squares", numCells)
+				continue
+			}
+			for i, flags := range data {
+				if flags&0x80 != 0 {
+					if puz.Circles == nil {
+						puz.Circles = make([]bool, numCells)
+					}
+					puz.Circles[i] = true
+				}
+			}
+		case "GRBS":
+			if length != numCells {
+				logger.Warn("skipping puz section of the wrong size", "section", name, "length", length, "squares", numCells)
+				continue
+			}
+			rebusSquares = data
+		case "RTBL":
+			decoded, err := charmap.ISO8859_1.NewDecoder().Bytes(data)
+			if err != nil {
+				logger.Warn("skipping unreadable puz section", "section", name, "err", err)
+				continue
+			}
+			rebusTable = string(decoded)
+		}
+	}
+
+	if rebusSquares == nil {
+		return
+	}
+	answers := make(map[int]string)
+	for _, entry := range strings.Split(rebusTable, ";") {
+		key, answer, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+		num, err := strconv.Atoi(strings.TrimSpace(key))
+		if err != nil {
+			logger.Warn("skipping malformed rebus table entry", "entry", entry)
+			continue
+		}
+		answers[num] = answer
+	}
+	for i, square := range rebusSquares {
+		if square == 0 {
+			continue
+		}
+		if puz.Rebus == nil {
+			puz.Rebus = make(map[int]string)
+		}
+		puz.Rebus[i] = answers[int(square)-1]
+	}
+}
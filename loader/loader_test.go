@@ -0,0 +1,124 @@
+package loader
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+// testPuzzle is a small puzzle with a block, a circle and a rebus square.
+func testPuzzle() puzzle.PuzzleDefinition {
+	puz := puzzle.PuzzleDefinition{
+		Title:     "Test",
+		Author:    "Tester",
+		Copyright: "© Nobody",
+		Notes:     "Some notes",
+		NumRows:   3,
+		NumCols:   4,
+		Answer:    "TARS" + "ERA." + "NETS",
+		Circles:   []bool{false, true, false, false, false, false, false, false, false, false, false, false},
+		Rebus:     map[int]string{0: "TEN"},
+	}
+	puz.CurrentState = emptyState(puz.Answer)
+	puz.AssignNumberedClues(
+		map[int]string{1: "Clue one across", 5: "See 1-Across", 6: "Mesh"},
+		map[int]string{1: "Ten", 2: "Be", 3: "Mouse", 4: "Kind of sum"},
+	)
+	puz.NumClues = len(puz.AcrossClues) + len(puz.DownClues)
+	return puz
+}
+
+// section encodes a puz extension section, leaving its checksum empty.
+func section(name string, length int, data []byte) []byte {
+	header := make([]byte, 8)
+	copy(header, name)
+	binary.LittleEndian.PutUint16(header[4:6], uint16(length))
+	return append(append(header, data...), 0)
+}
+
+func TestDecodePuzSkipsMalformedSections(t *testing.T) {
+	puz := testPuzzle()
+	puz.Circles, puz.Rebus = nil, nil
+	data, err := encodePuz(&puz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numCells := puz.NumRows * puz.NumCols
+	circles := make([]byte, numCells)
+	circles[2] = 0x80
+
+	tests := []struct {
+		name     string
+		sections []byte
+		circled  []int
+	}{
+		{"no sections", nil, nil},
+		{"circles", section("GEXT", numCells, circles), []int{2}},
+		{"circles of the wrong size", section("GEXT", 3, []byte{0x80, 0x80, 0x80}), nil},
+		{"rebus of the wrong size", section("GRBS", 2, []byte{1, 0}), nil},
+		{"unknown section", section("LTIM", 3, []byte("0,0")), nil},
+		{"bad rebus table", slices.Concat(
+			section("GRBS", numCells, make([]byte, numCells)),
+			section("RTBL", 5, []byte("xx:AB")),
+		), nil},
+		{"truncated section", section("GEXT", numCells, circles)[:12], nil},
+		{"truncated header", []byte("GEX"), nil},
+		{"valid after a bad one", slices.Concat(
+			section("GRBS", 2, []byte{1, 0}),
+			section("GEXT", numCells, circles),
+		), []int{2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Decode(slices.Concat(data, test.sections), ".puz")
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if got.Answer != puz.Answer || len(got.AcrossClues) != len(puz.AcrossClues) {
+				t.Errorf("got answer %q with %d across clues, want %q with %d",
+					got.Answer, len(got.AcrossClues), puz.Answer, len(puz.AcrossClues))
+			}
+			var circled []int
+			for i, circle := range got.Circles {
+				if circle {
+					circled = append(circled, i)
+				}
+			}
+			if !slices.Equal(circled, test.circled) {
+				t.Errorf("circled squares %v, want %v", circled, test.circled)
+			}
+		})
+	}
+}
+
+func TestDecodePuzChecksClueCount(t *testing.T) {
+	puz := testPuzzle()
+	data, err := encodePuz(&puz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, numClues := range []int{0, 1, puz.NumClues - 1, puz.NumClues + 1} {
+		bad := slices.Clone(data)
+		binary.LittleEndian.PutUint16(bad[0x2E:], uint16(numClues))
+		if _, err := Decode(bad, ".puz"); !errors.Is(err, ErrFileParse) {
+			t.Errorf("with %d clues in the header: err = %v, want ErrFileParse", numClues, err)
+		}
+	}
+}
+
+func TestDecodeRefusesGridsWithoutEntries(t *testing.T) {
+	puz := puzzle.PuzzleDefinition{NumRows: 2, NumCols: 2, Answer: "....", CurrentState: "...."}
+	for _, format := range Formats {
+		path := filepath.Join(t.TempDir(), "blocks"+format)
+		if err := SaveFile(path, &puz); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if _, err := LoadFile(path); !errors.Is(err, ErrFileParse) {
+			t.Errorf("%s: loading a grid of blocks: err = %v, want ErrFileParse", format, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var puzFileMagic = "ACROSS&DOWN\x00"
+var puzChecksumMagic = "ICHEATED"
+
+// SavePuzFile writes puz to path in the .puz format, including all checksums
+// so that other solvers accept the file.
+func SavePuzFile(path string, puz *puzzle.PuzzleDefinition) error {
+	data, err := encodePuz(puz)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func encodePuz(puz *puzzle.PuzzleDefinition) ([]byte, error) {
+	encoder := charmap.ISO8859_1.NewEncoder()
+	encode := func(s string) ([]byte, error) {
+		if !isLatin1(s) {
+			s = strings.Map(func(r rune) rune {
+				if r > 0xFF {
+					return '?'
+				}
+				return r
+			}, s)
+		}
+		return encoder.Bytes([]byte(s))
+	}
+
+	title, err := encode(puz.Title)
+	if err != nil {
+		return nil, err
+	}
+	author, err := encode(puz.Author)
+	if err != nil {
+		return nil, err
+	}
+	copyright, err := encode(puz.Copyright)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := encode(puz.Notes)
+	if err != nil {
+		return nil, err
+	}
+	orderedClues := puzClueOrder(puz)
+	clues := make([][]byte, len(orderedClues))
+	for i, clue := range orderedClues {
+		if clues[i], err = encode(clue.Clue); err != nil {
+			return nil, err
+		}
+	}
+	answer := []byte(puz.Answer)
+	state := []byte(puz.CurrentState)
+
+	header := make([]byte, 0x34)
+	copy(header[0x02:], puzFileMagic)
+	version := puz.Version
+	if version == "" {
+		version = "1.3"
+	}
+	copy(header[0x18:0x1B], version)
+	binary.LittleEndian.PutUint16(header[0x1E:], puz.LockChecksum)
+	header[0x2C] = byte(puz.NumCols)
+	header[0x2D] = byte(puz.NumRows)
+	binary.LittleEndian.PutUint16(header[0x2E:], uint16(len(clues)))
+	binary.LittleEndian.PutUint16(header[0x30:], 0x0001)
+	if puz.Locked {
+		binary.LittleEndian.PutUint16(header[0x32:], 0x0004)
+	}
+
+	headerChecksum := checksumRegion(header[0x2C:0x34], 0)
+	binary.LittleEndian.PutUint16(header[0x0E:], headerChecksum)
+
+	// Notes only count towards checksums from version 1.3 of the format.
+	checksummedNotes := notes
+	if version < "1.3" {
+		checksummedNotes = nil
+	}
+	textChecksum := puzTextChecksum(title, author, copyright, clues, checksummedNotes, 0)
+
+	checksums := []uint16{
+		headerChecksum,
+		checksumRegion(answer, 0),
+		checksumRegion(state, 0),
+		textChecksum,
+	}
+	for i, checksum := range checksums {
+		header[0x10+i] = puzChecksumMagic[i] ^ byte(checksum&0xFF)
+		header[0x14+i] = puzChecksumMagic[i+4] ^ byte(checksum>>8)
+	}
+
+	globalChecksum := checksumRegion(answer, headerChecksum)
+	globalChecksum = checksumRegion(state, globalChecksum)
+	globalChecksum = puzTextChecksum(title, author, copyright, clues, checksummedNotes, globalChecksum)
+	binary.LittleEndian.PutUint16(header[0x00:], globalChecksum)
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.Write(answer)
+	buf.Write(state)
+	for _, field := range [][]byte{title, author, copyright} {
+		buf.Write(field)
+		buf.WriteByte(0)
+	}
+	for _, clue := range clues {
+		buf.Write(clue)
+		buf.WriteByte(0)
+	}
+	buf.Write(notes)
+	buf.WriteByte(0)
+
+	if len(puz.Rebus) > 0 {
+		rebusSquares, rebusTable := encodeRebus(puz)
+		writeExtension(&buf, "GRBS", rebusSquares)
+		table, err := encode(rebusTable)
+		if err != nil {
+			return nil, err
+		}
+		writeExtension(&buf, "RTBL", table)
+	}
+	if slices.Contains(puz.Circles, true) {
+		markup := make([]byte, len(puz.Answer))
+		for i, circled := range puz.Circles {
+			if circled {
+				markup[i] = 0x80
+			}
+		}
+		writeExtension(&buf, "GEXT", markup)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeExtension(buf *bytes.Buffer, name string, data []byte) {
+	buf.WriteString(name)
+	binary.Write(buf, binary.LittleEndian, uint16(len(data)))
+	binary.Write(buf, binary.LittleEndian, checksumRegion(data, 0))
+	buf.Write(data)
+	buf.WriteByte(0)
+}
+
+// encodeRebus numbers each distinct rebus answer, returning the GRBS square
+// data that points into the RTBL table of answers.
+func encodeRebus(puz *puzzle.PuzzleDefinition) ([]byte, string) {
+	squares := make([]byte, len(puz.Answer))
+	keys := make(map[string]int)
+	var table strings.Builder
+	for i := range len(puz.Answer) {
+		answer, ok := puz.Rebus[i]
+		if !ok {
+			continue
+		}
+		key, ok := keys[answer]
+		if !ok {
+			key = len(keys)
+			keys[answer] = key
+			fmt.Fprintf(&table, "%2d:%s;", key, answer)
+		}
+		squares[i] = byte(key + 1)
+	}
+	return squares, table.String()
+}
+
+// puzTextChecksum covers the null-terminated title, author, copyright and
+// notes along with the unterminated clue strings. Empty fields are skipped.
+func puzTextChecksum(title, author, copyright []byte, clues [][]byte, notes []byte, checksum uint16) uint16 {
+	for _, field := range [][]byte{title, author, copyright} {
+		if len(field) > 0 {
+			checksum = checksumRegion(append(slices.Clone(field), 0), checksum)
+		}
+	}
+	for _, clue := range clues {
+		checksum = checksumRegion(clue, checksum)
+	}
+	if len(notes) > 0 {
+		checksum = checksumRegion(append(slices.Clone(notes), 0), checksum)
+	}
+	return checksum
+}
+
+// puzClueOrder lists clues the way .puz stores them: by number, with an
+// across clue ahead of the down clue sharing its number.
+func puzClueOrder(puz *puzzle.PuzzleDefinition) []*puzzle.Clue {
+	clues := slices.Concat(puz.AcrossClues, puz.DownClues)
+	slices.SortStableFunc(clues, func(a, b *puzzle.Clue) int {
+		if a.Num != b.Num {
+			return a.Num - b.Num
+		}
+		return int(a.Direction) - int(b.Direction)
+	})
+	return clues
+}
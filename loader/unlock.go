@@ -0,0 +1,115 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+var ErrNotLocked = fmt.Errorf("puzzle solution is not locked")
+var ErrWrongKey = fmt.Errorf("key does not unlock the puzzle solution")
+var ErrKeyNotFound = fmt.Errorf("no key unlocks the puzzle solution")
+
+// Unlock unscrambles the solution of a locked .puz puzzle using its four
+// digit key. The key is checked against the checksum stored in the file.
+func Unlock(puz *puzzle.PuzzleDefinition, key int) error {
+	if !puz.Locked {
+		return ErrNotLocked
+	}
+	answer, ok := unscrambleSolution(puz, key)
+	if !ok {
+		return ErrWrongKey
+	}
+	puz.Answer = answer
+	puz.Locked = false
+	puz.LockChecksum = 0
+	puz.UpdateClueAnswers()
+	return nil
+}
+
+// FindUnlockKey tries every possible key until one unlocks the solution.
+func FindUnlockKey(puz *puzzle.PuzzleDefinition) (int, error) {
+	if !puz.Locked {
+		return 0, ErrNotLocked
+	}
+	for key := 0; key <= 9999; key++ {
+		if _, ok := unscrambleSolution(puz, key); ok {
+			return key, nil
+		}
+	}
+	return 0, ErrKeyNotFound
+}
+
+// unscrambleSolution reverses the .puz scrambling scheme: the letters of the
+// solution are read column by column, then every digit of the key is undone
+// in reverse as a shuffle, a rotation and a shift of each letter.
+func unscrambleSolution(puz *puzzle.PuzzleDefinition, key int) (string, bool) {
+	digits := keyDigits(key)
+	columnMajor := transpose(puz.Answer, puz.NumCols, puz.NumRows)
+	letters := []byte(strings.ReplaceAll(columnMajor, ".", ""))
+	for _, b := range letters {
+		if b < 'A' || b > 'Z' {
+			return "", false
+		}
+	}
+
+	length := len(letters)
+	for i := len(digits) - 1; i >= 0; i-- {
+		// Scrambling rotates by each digit, which can't be done to fewer
+		// letters than that.
+		if digits[i] > length {
+			return "", false
+		}
+		letters = unshuffle(letters)
+		letters = append(letters[length-digits[i]:], letters[:length-digits[i]]...)
+		for j := range letters {
+			letters[j] = byte('A' + (int(letters[j]-'A')-digits[j%len(digits)]+26)%26)
+		}
+	}
+
+	if checksumRegion(letters, 0) != puz.LockChecksum {
+		return "", false
+	}
+	restored := restoreBlackSquares(columnMajor, letters)
+	return transpose(restored, puz.NumRows, puz.NumCols), true
+}
+
+func keyDigits(key int) []int {
+	return []int{key / 1000 % 10, key / 100 % 10, key / 10 % 10, key % 10}
+}
+
+// transpose reads a row-major grid of the given width and height column by
+// column.
+func transpose(grid string, width, height int) string {
+	var sb strings.Builder
+	for col := range width {
+		for row := range height {
+			sb.WriteByte(grid[row*width+col])
+		}
+	}
+	return sb.String()
+}
+
+func unshuffle(letters []byte) []byte {
+	unshuffled := make([]byte, 0, len(letters))
+	for i := 1; i < len(letters); i += 2 {
+		unshuffled = append(unshuffled, letters[i])
+	}
+	for i := 0; i < len(letters); i += 2 {
+		unshuffled = append(unshuffled, letters[i])
+	}
+	return unshuffled
+}
+
+func restoreBlackSquares(grid string, letters []byte) string {
+	restored := []byte(grid)
+	next := 0
+	for i := range restored {
+		if restored[i] != '.' {
+			restored[i] = letters[next]
+			next++
+		}
+	}
+	return string(restored)
+}
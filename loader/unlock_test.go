@@ -0,0 +1,78 @@
+package loader
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// scramble locks answer with key the way .puz files are scrambled, which is
+// unscrambleSolution in reverse. It returns the scrambled answer and its
+// checksum.
+func scramble(answer string, width, height, key int) (string, uint16) {
+	digits := keyDigits(key)
+	columnMajor := transpose(answer, width, height)
+	letters := []byte(strings.ReplaceAll(columnMajor, ".", ""))
+	checksum := checksumRegion(letters, 0)
+	for _, digit := range digits {
+		for j := range letters {
+			letters[j] = byte('A' + (int(letters[j]-'A')+digits[j%len(digits)])%26)
+		}
+		letters = append(letters[digit:], letters[:digit]...)
+		mid := len(letters) / 2
+		shuffled := make([]byte, 0, len(letters))
+		for i := range mid {
+			shuffled = append(shuffled, letters[mid+i], letters[i])
+		}
+		if len(letters)%2 == 1 {
+			shuffled = append(shuffled, letters[len(letters)-1])
+		}
+		letters = shuffled
+	}
+	return transpose(restoreBlackSquares(columnMajor, letters), height, width), checksum
+}
+
+func TestUnlock(t *testing.T) {
+	puz := testPuzzle()
+	answer := puz.Answer
+	puz.Answer, puz.LockChecksum = scramble(answer, puz.NumCols, puz.NumRows, 4721)
+	puz.Locked = true
+	data, err := encodePuz(&puz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked, err := Decode(data, ".puz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked.Locked || locked.Answer == answer {
+		t.Fatalf("decoded as locked %v with answer %q", locked.Locked, locked.Answer)
+	}
+
+	if err := Unlock(&locked, 1234); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("unlocking with the wrong key: err = %v, want ErrWrongKey", err)
+	}
+	key, err := FindUnlockKey(&locked)
+	if err != nil || key != 4721 {
+		t.Fatalf("FindUnlockKey = %d, %v, want 4721", key, err)
+	}
+	if err := Unlock(&locked, key); err != nil {
+		t.Fatal(err)
+	}
+	if locked.Locked || locked.Answer != answer || locked.AcrossClues[0].Answer != puz.AcrossClues[0].Answer {
+		t.Errorf("unlocked to %q, want %q", locked.Answer, answer)
+	}
+	if err := Unlock(&locked, key); !errors.Is(err, ErrNotLocked) {
+		t.Errorf("unlocking twice: err = %v, want ErrNotLocked", err)
+	}
+}
+
+func TestUnlockShortSolution(t *testing.T) {
+	puz := testPuzzle()
+	puz.NumRows, puz.NumCols = 2, 2
+	puz.Answer, puz.Locked = "AB"+"CD", true
+	// Digits larger than the number of letters can't have scrambled it.
+	if _, err := FindUnlockKey(&puz); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("err = %v, want ErrKeyNotFound", err)
+	}
+}
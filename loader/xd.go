@@ -0,0 +1,212 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+// xd file definition: https://github.com/century-arcade/xd/blob/master/doc/xd-format.md
+// A file is made of metadata headers, the grid, the clues and free-form
+// notes, each separated by blank lines. Circled squares are written in
+// lowercase and rebus squares as keys into the Rebus header.
+var xdCluePattern = regexp.MustCompile(`^([AD])(\d+)\.\s*(.*?)\s*~\s*(\S*)\s*$`)
+
+// xdRebusKeys are the characters used for rebus squares in the grid.
+const xdRebusKeys = "0123456789@$%&*+?!<>=^"
+
+func decodeXd(data []byte) (puzzle.PuzzleDefinition, error) {
+	puz := puzzle.PuzzleDefinition{}
+	rebusKeys := make(map[rune]string)
+	var gridRows []string
+	var notes []string
+	across := make(map[int]string)
+	down := make(map[int]string)
+
+	// Sections are read in order: headers, grid, clues and then notes.
+	section := 0
+	hasHeaders := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" {
+			if section == 0 && hasHeaders || section == 1 && len(gridRows) > 0 {
+				section++
+			}
+			if section == 3 && len(notes) > 0 {
+				notes = append(notes, "")
+			}
+			continue
+		}
+
+		switch section {
+		case 0:
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				// Headerless files start straight with the grid.
+				section = 1
+				gridRows = append(gridRows, line)
+				continue
+			}
+			hasHeaders = true
+			value = strings.TrimSpace(value)
+			switch strings.ToLower(key) {
+			case "title":
+				puz.Title = value
+			case "author":
+				puz.Author = value
+			case "copyright":
+				puz.Copyright = value
+			case "notes":
+				puz.Notes = value
+			case "rebus":
+				for _, entry := range strings.Fields(value) {
+					k, answer, ok := strings.Cut(entry, "=")
+					if !ok || len([]rune(k)) != 1 {
+						return puzzle.PuzzleDefinition{}, ErrFileParse
+					}
+					rebusKeys[[]rune(k)[0]] = strings.ToUpper(answer)
+				}
+			}
+		case 1:
+			gridRows = append(gridRows, line)
+		default:
+			match := xdCluePattern.FindStringSubmatch(line)
+			if match == nil || section == 3 {
+				section = 3
+				notes = append(notes, line)
+				continue
+			}
+			num, _ := strconv.Atoi(match[2])
+			if match[1] == "A" {
+				across[num] = match[3]
+			} else {
+				down[num] = match[3]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return puzzle.PuzzleDefinition{}, err
+	}
+	if len(gridRows) == 0 {
+		return puzzle.PuzzleDefinition{}, ErrFileParse
+	}
+
+	puz.NumRows = len(gridRows)
+	puz.NumCols = len([]rune(gridRows[0]))
+	numCells := puz.NumRows * puz.NumCols
+	answer := make([]byte, 0, numCells)
+	for row, line := range gridRows {
+		squares := []rune(line)
+		if len(squares) != puz.NumCols {
+			return puzzle.PuzzleDefinition{}, ErrFileParse
+		}
+		for col, square := range squares {
+			i := row*puz.NumCols + col
+			switch {
+			case square == '#' || square == '_':
+				answer = append(answer, '.')
+			case square >= 'A' && square <= 'Z':
+				answer = append(answer, byte(square))
+			case square >= 'a' && square <= 'z':
+				if puz.Circles == nil {
+					puz.Circles = make([]bool, numCells)
+				}
+				puz.Circles[i] = true
+				answer = append(answer, byte(square-'a'+'A'))
+			default:
+				full, ok := rebusKeys[square]
+				if !ok || full == "" {
+					return puzzle.PuzzleDefinition{}, ErrFileParse
+				}
+				if puz.Rebus == nil {
+					puz.Rebus = make(map[int]string)
+				}
+				puz.Rebus[i] = full
+				answer = append(answer, squareLetter(full))
+			}
+		}
+	}
+	puz.Answer = string(answer)
+	puz.CurrentState = emptyState(puz.Answer)
+	if len(notes) > 0 {
+		puz.Notes = strings.TrimSpace(strings.Join(slices.Insert(notes, 0, puz.Notes), "\n"))
+	}
+	puz.AssignNumberedClues(across, down)
+	if !hasEntries(&puz) {
+		return puzzle.PuzzleDefinition{}, ErrFileParse
+	}
+	return puz, nil
+}
+
+func saveXdFile(path string, puz *puzzle.PuzzleDefinition) error {
+	if puz.Locked {
+		return ErrLockedSolution
+	}
+
+	keys := make(map[string]rune)
+	var rebusHeader []string
+	for i := range len(puz.Answer) {
+		full, ok := puz.Rebus[i]
+		if !ok {
+			continue
+		}
+		if _, ok := keys[full]; !ok {
+			if len(keys) == len(xdRebusKeys) {
+				return fmt.Errorf("too many distinct rebus answers for xd (at most %d)", len(xdRebusKeys))
+			}
+			key := rune(xdRebusKeys[len(keys)])
+			keys[full] = key
+			rebusHeader = append(rebusHeader, fmt.Sprintf("%c=%s", key, full))
+		}
+	}
+
+	var sb strings.Builder
+	for _, header := range [][2]string{{"Title", puz.Title}, {"Author", puz.Author}, {"Copyright", puz.Copyright}} {
+		if header[1] != "" {
+			fmt.Fprintf(&sb, "%s: %s\n", header[0], header[1])
+		}
+	}
+	if len(rebusHeader) > 0 {
+		fmt.Fprintf(&sb, "Rebus: %s\n", strings.Join(rebusHeader, " "))
+	}
+	sb.WriteString("\n\n")
+
+	for row := range puz.NumRows {
+		for col := range puz.NumCols {
+			i := row*puz.NumCols + col
+			square := rune(puz.Answer[i])
+			switch {
+			case square == '.':
+				square = '#'
+			case puz.Rebus[i] != "":
+				square = keys[puz.Rebus[i]]
+			case i < len(puz.Circles) && puz.Circles[i] && square >= 'A' && square <= 'Z':
+				square = square - 'A' + 'a'
+			}
+			sb.WriteRune(square)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n\n")
+
+	for _, clue := range puz.AcrossClues {
+		fmt.Fprintf(&sb, "A%d. %s ~ %s\n", clue.Num, clue.Clue, FullAnswer(clue, puz.Rebus, puz.NumCols))
+	}
+	sb.WriteString("\n")
+	for _, clue := range puz.DownClues {
+		fmt.Fprintf(&sb, "D%d. %s ~ %s\n", clue.Num, clue.Clue, FullAnswer(clue, puz.Rebus, puz.NumCols))
+	}
+
+	if puz.Notes != "" {
+		fmt.Fprintf(&sb, "\n\n%s\n", puz.Notes)
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
@@ -1,26 +1,70 @@
+// Package logger is cruciterm's debug log. Logging is off until Open is
+// called, and every function is safe to use before that.
 package logger
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 )
 
-type Logger struct {
-	logFile *os.File
+// LevelOff is above every level slog defines, so nothing is logged.
+const LevelOff = slog.Level(100)
+
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	logger.Store(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: LevelOff})))
 }
 
-var logger Logger
+// ParseLevel reads a level name: debug, info, warn, error or off.
+func ParseLevel(name string) (slog.Level, error) {
+	if strings.EqualFold(name, "off") {
+		return LevelOff, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return LevelOff, fmt.Errorf("unknown log level %q", name)
+	}
+	return level, nil
+}
+
+// Open starts appending records at level or above to the file at path,
+// creating it and its directory if needed. The returned file must be closed
+// once logging is done.
+func Open(path string, level slog.Level) (io.Closer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	SetOutput(f, level)
+	return f, nil
+}
+
+// SetOutput sends records at level or above to w.
+func SetOutput(w io.Writer, level slog.Level) {
+	logger.Store(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})))
+}
+
+func Debug(msg string, args ...any) {
+	logger.Load().Debug(msg, args...)
+}
 
-func SetLogFile(f *os.File) {
-	logger.logFile = f
+func Info(msg string, args ...any) {
+	logger.Load().Info(msg, args...)
 }
 
-func Debug(msg string) {
-	msg += "\n"
-	logger.logFile.WriteString(msg)
+func Warn(msg string, args ...any) {
+	logger.Load().Warn(msg, args...)
 }
 
-func Debugf(format string, a ...any) {
-	format += "\n"
-	logger.logFile.WriteString(fmt.Sprintf(format, a...))
+func Error(msg string, args ...any) {
+	logger.Load().Error(msg, args...)
 }
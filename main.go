@@ -1,39 +1,222 @@
 package main
 
 import (
+	"cmp"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
-	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/tylerwgrass/cruciterm/config"
 	"github.com/tylerwgrass/cruciterm/loader"
 	"github.com/tylerwgrass/cruciterm/logger"
 	"github.com/tylerwgrass/cruciterm/preferences"
-	"github.com/tylerwgrass/cruciterm/solver"
-	"github.com/tylerwgrass/cruciterm/theme"
+	"github.com/tylerwgrass/cruciterm/puzzle"
 )
 
-var TEST_FILE_PATH string = "./puzzles/test.puz"
+const (
+	envLogFile  = "CRUCITERM_LOG_FILE"
+	envLogLevel = "CRUCITERM_LOG_LEVEL"
+)
+
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitUsage    = 2
+	exitUnsolved = 3
+)
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(cmd *command, args []string) int
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "solve", args: "<file>...", summary: "Solve puzzles in the terminal, one tab per file", run: runSolve},
+		{name: "library", args: "[dir...]", summary: "Browse the puzzles in your library, or in the given directories", run: runLibrary},
+		{name: "fetch", args: "[source...]", summary: "Download puzzles from the sources in your config into your library", run: runFetch},
+		{name: "info", args: "<file>", summary: "Show puzzle metadata", run: runInfo},
+		{name: "convert", args: "<input>...", summary: "Convert puzzles between .puz, ipuz, xd and JSON", run: runConvert},
+		{name: "edit", args: "<file>", summary: "Build a puzzle in the grid editor, starting a new one if the file doesn't exist", run: runEdit},
+		{name: "fill", args: "<file>", summary: "Fill the empty squares of a grid with words from a word list", run: runFill},
+		{name: "host", args: "<file>", summary: "Share a puzzle over the network to solve together, or race with -race", run: runHost},
+		{name: "join", args: "<address>", summary: "Join a co-op puzzle shared with \"cruciterm host\"", run: runJoin},
+		{name: "serve", args: "[dir...]", summary: "Let others solve the puzzles in your library over SSH", run: runServe},
+		{name: "watch", args: "<address>", summary: "Watch a co-op puzzle shared with \"cruciterm host\" without playing", run: runWatch},
+		{name: "replay", args: "<file>", summary: "Play back your recorded solve of a puzzle", run: runReplay},
+		{name: "stats", args: "", summary: "Show your solve history", run: runStats},
+		{name: "check", args: "<file>", summary: "Check the fill saved in a puzzle file", run: runCheck},
+		{name: "print", args: "<file>", summary: "Print a puzzle and its clues as plain text", run: runPrint},
+		{name: "unlock", args: "<file>", summary: "Unlock a puzzle with a scrambled solution", run: runUnlock},
+	}
+}
 
 func main() {
-	f, err := tea.LogToFile("debug.log", "debug")
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		cmd := findCommand("library")
+		return cmd.run(cmd, args)
+	}
+
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			if cmd := findCommand(args[1]); cmd != nil {
+				return cmd.run(cmd, []string{"-h"})
+			}
+			fmt.Fprintf(os.Stderr, "cruciterm: unknown command %q\n", args[1])
+			return exitUsage
+		}
+		usage(os.Stdout)
+		return exitOK
+	}
+
+	if cmd := findCommand(name); cmd != nil {
+		return cmd.run(cmd, args[1:])
+	}
+
+	// A bare puzzle path is shorthand for solving it.
+	if _, err := os.Stat(name); err == nil {
+		cmd := findCommand("solve")
+		return cmd.run(cmd, args)
+	}
+
+	fmt.Fprintf(os.Stderr, "cruciterm: unknown command %q\n\n", name)
+	usage(os.Stderr)
+	return exitUsage
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func usage(out io.Writer) {
+	var sb strings.Builder
+	sb.WriteString("cruciterm solves crossword puzzles in the terminal.\n\n")
+	sb.WriteString("Usage:\n")
+	sb.WriteString("  cruciterm <command> [flags] [arguments]\n")
+	sb.WriteString("  cruciterm <file>    same as \"cruciterm solve <file>\"\n")
+	sb.WriteString("  cruciterm           same as \"cruciterm library\"\n\n")
+	sb.WriteString("Commands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&sb, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	sb.WriteString("\nRun \"cruciterm help <command>\" for more about a command.\n")
+	fmt.Fprint(out, sb.String())
+}
+
+func newFlagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: cruciterm %s [flags] %s\n\n%s.\n\nFlags:\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args into fs and checks the number of positional
+// arguments, returning false with the exit code to use when they are invalid.
+func parseFlags(fs *flag.FlagSet, args []string, numArgs int) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if numArgs >= 0 && fs.NArg() != numArgs {
+		fs.Usage()
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// globalOptions are the flags shared by every command.
+type globalOptions struct {
+	configPath string
+	logFile    string
+	logLevel   string
+}
+
+func (g *globalOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.configPath, "config", "", "path to a JSON config file (default "+config.ConfigDir()+"/config.json)")
+	fs.StringVar(&g.logFile, "log-file", "", "log to this file instead of "+config.LogPath()+", at debug level unless\n-log-level is given ($"+envLogFile+")")
+	fs.StringVar(&g.logLevel, "log-level", "", "enable logging at this level: debug, info, warn, error or off ($"+envLogLevel+")")
+}
+
+// setup loads the config file and starts logging. The returned function
+// must be called once the command is done.
+func (g globalOptions) setup() (config.Config, func(), error) {
+	cfg, err := config.Load(g.configPath)
 	if err != nil {
-		fmt.Println("fatal:", err)
-		os.Exit(1)
+		return cfg, func() {}, err
 	}
-	os.Truncate("debug.log", 0)
-	logger.SetLogFile(f)
-	defer f.Close()
-	puzFilePath := TEST_FILE_PATH
-	if len(os.Args) == 2 {
-		puzFilePath = os.Args[1]
+
+	logFile := cmp.Or(g.logFile, os.Getenv(envLogFile), cfg.LogFile)
+	levelName := cmp.Or(g.logLevel, os.Getenv(envLogLevel), cfg.LogLevel)
+	if levelName == "" && logFile != "" {
+		levelName = "debug"
+	}
+	if levelName == "" {
+		return cfg, func() {}, nil
+	}
+	level, err := logger.ParseLevel(levelName)
+	if err != nil || level == logger.LevelOff {
+		return cfg, func() {}, err
+	}
+	f, err := logger.Open(cmp.Or(logFile, config.LogPath()), level)
+	if err != nil {
+		return cfg, func() {}, err
+	}
+	logger.Info("starting", "args", os.Args[1:])
+	return cfg, func() { f.Close() }, nil
+}
+
+// configPreferences returns the default preferences with those set in cfg
+// changed.
+func configPreferences(cfg config.Config) (preferences.Preferences, error) {
+	prefs := preferences.Defaults()
+	for name, value := range cfg.Preferences {
+		pref, ok := preferences.Parse(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown preference %q in config", name)
+		}
+		prefs.SetBool(pref, value)
 	}
+	return prefs, nil
+}
 
-	puz, err := loader.LoadFile(puzFilePath)
+func loadPuzzle(path string) (puzzle.PuzzleDefinition, error) {
+	puz, err := loader.LoadFile(path)
 	if err != nil {
-		fmt.Println(err)
-		return
+		logger.Warn("could not load puzzle", "path", path, "err", err)
+	} else {
+		logger.Debug("loaded puzzle", "path", path, "title", puz.Title, "rows", puz.NumRows, "cols", puz.NumCols)
 	}
-	theme.Init()
-	preferences.Init()
-	solver.Run(&puz)
+	var pathErr *os.PathError
+	if err != nil && !errors.As(err, &pathErr) {
+		return puz, fmt.Errorf("%s: %w", path, err)
+	}
+	return puz, err
+}
+
+func fail(cmd *command, err error) int {
+	fmt.Fprintf(os.Stderr, "cruciterm %s: %v\n", cmd.name, err)
+	return exitFailure
 }
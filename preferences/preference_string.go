@@ -13,11 +13,13 @@ func _() {
 	_ = x[SwapCursorOnDirectionChange-2]
 	_ = x[WrapAtEndOfGrid-3]
 	_ = x[WrapOnArrowNavigation-4]
+	_ = x[ShowNotesOnOpen-5]
+	_ = x[ShowWrongSquareCount-6]
 }
 
-const _Preference_name = "JumpToEmptySquareSwapCursorOnGridWrapSwapCursorOnDirectionChangeWrapAtEndOfGridWrapOnArrowNavigation"
+const _Preference_name = "JumpToEmptySquareSwapCursorOnGridWrapSwapCursorOnDirectionChangeWrapAtEndOfGridWrapOnArrowNavigationShowNotesOnOpenShowWrongSquareCount"
 
-var _Preference_index = [...]uint8{0, 17, 37, 64, 79, 100}
+var _Preference_index = [...]uint8{0, 17, 37, 64, 79, 100, 115, 135}
 
 func (i Preference) String() string {
 	if i < 0 || i >= Preference(len(_Preference_index)-1) {
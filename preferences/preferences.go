@@ -11,9 +11,10 @@ type SetPreference struct {
 	Pref  Preference
 	Value interface{}
 }
-type Preferences map[Preference]interface{}
 
-var prefs Preferences
+// Preferences are one solver's settings, so that changing them in one SSH
+// session leaves everyone else's alone. Those not set have their default.
+type Preferences map[Preference]interface{}
 
 const (
 	JumpToEmptySquare Preference = iota
@@ -21,6 +22,8 @@ const (
 	SwapCursorOnDirectionChange
 	WrapAtEndOfGrid
 	WrapOnArrowNavigation
+	ShowNotesOnOpen
+	ShowWrongSquareCount
 )
 
 var defaultPreferences = Preferences{
@@ -29,19 +32,22 @@ var defaultPreferences = Preferences{
 	WrapOnArrowNavigation:       false,
 	WrapAtEndOfGrid:             true,
 	JumpToEmptySquare:           true,
+	ShowNotesOnOpen:             true,
+	ShowWrongSquareCount:        false,
 }
 
-func Init() {
-	prefs = maps.Clone(defaultPreferences)
+// Defaults returns a copy of the default preferences to change.
+func Defaults() Preferences {
+	return maps.Clone(defaultPreferences)
 }
 
-func ListPreferences() []SetPreference {
+func (p Preferences) List() []SetPreference {
 	preferenceSettings := make([]SetPreference, 0, len(defaultPreferences))
 
-	for key := JumpToEmptySquare; key <= WrapOnArrowNavigation; key++ {
+	for key := JumpToEmptySquare; key <= ShowWrongSquareCount; key++ {
 		prefSetting := SetPreference{
 			Pref:  key,
-			Value: prefs[key],
+			Value: p.Get(key),
 		}
 		preferenceSettings = append(preferenceSettings, prefSetting)
 	}
@@ -49,20 +55,33 @@ func ListPreferences() []SetPreference {
 	return preferenceSettings
 }
 
-func Get(k Preference) interface{} {
-	return prefs[k]
+// Parse looks up a preference by its name, e.g. "JumpToEmptySquare".
+func Parse(name string) (Preference, bool) {
+	for key := JumpToEmptySquare; key <= ShowWrongSquareCount; key++ {
+		if key.String() == name {
+			return key, true
+		}
+	}
+	return 0, false
+}
+
+func (p Preferences) Get(k Preference) interface{} {
+	if v, ok := p[k]; ok {
+		return v
+	}
+	return defaultPreferences[k]
 }
 
-func GetBool(k Preference) bool {
-	return Get(k).(bool)
+func (p Preferences) GetBool(k Preference) bool {
+	return p.Get(k).(bool)
 }
 
-func Set(k Preference, v interface{}) {
-	prefs[k] = v
+func (p Preferences) Set(k Preference, v interface{}) {
+	p[k] = v
 }
 
-func SetBool(k Preference, v bool) {
-	Set(k, v)
+func (p Preferences) SetBool(k Preference, v bool) {
+	p.Set(k, v)
 }
 
 func (p Preferences) String() string {
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+func runPrint(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	solution := fs.Bool("solution", false, "fill the grid with the solution")
+	blank := fs.Bool("blank", false, "print an empty grid instead of the saved fill")
+	noClues := fs.Bool("no-clues", false, "only print the grid")
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+
+	_, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+
+	puz, err := loadPuzzle(fs.Arg(0))
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if *solution && puz.Locked {
+		return fail(cmd, fmt.Errorf("%s: the solution is locked; run \"cruciterm unlock\" first", fs.Arg(0)))
+	}
+
+	fill := puz.CurrentState
+	if *solution {
+		fill = puz.Answer
+	}
+
+	fmt.Println(puz.Title)
+	fmt.Printf("%s %s\n\n", puz.Author, puz.Copyright)
+	for row := range puz.NumRows {
+		var sb strings.Builder
+		for col := range puz.NumCols {
+			square := fill[row*puz.NumCols+col]
+			switch {
+			case puz.Answer[row*puz.NumCols+col] == '.':
+				sb.WriteString("#")
+			case *blank || square == '-':
+				sb.WriteString("_")
+			default:
+				sb.WriteByte(square)
+			}
+			if col < puz.NumCols-1 {
+				sb.WriteString(" ")
+			}
+		}
+		fmt.Println(sb.String())
+	}
+
+	if *noClues {
+		return exitOK
+	}
+	printClues("ACROSS", puz.AcrossClues)
+	printClues("DOWN", puz.DownClues)
+	return exitOK
+}
+
+func printClues(heading string, clues []*puzzle.Clue) {
+	fmt.Printf("\n%s\n", heading)
+	for _, clue := range clues {
+		fmt.Printf("%4d. %s (%d)\n", clue.Num, clue.Clue, len(clue.Answer))
+	}
+}
@@ -0,0 +1,73 @@
+package progress
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/config"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+var ErrNoProgress = errors.New("no saved progress for puzzle")
+
+// Progress is the saved state of a partially or fully solved puzzle.
+type Progress struct {
+	Hash    string        `json:"hash"`
+	Title   string        `json:"title"`
+	State   string        `json:"state"`
+	Elapsed time.Duration `json:"elapsed"`
+	Solved  bool          `json:"solved"`
+	Checks  int           `json:"checks,omitempty"`
+	Reveals int           `json:"reveals,omitempty"`
+	// Corrections and LettersTyped describe the editing done so far.
+	Corrections  int       `json:"corrections,omitempty"`
+	LettersTyped int       `json:"lettersTyped,omitempty"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Store keeps one progress file per puzzle in a directory.
+type Store struct {
+	Dir string
+}
+
+func DefaultStore() Store {
+	return Store{Dir: filepath.Join(config.StateDir(), "progress")}
+}
+
+func (s Store) path(hash string) string {
+	return filepath.Join(s.Dir, hash+".json")
+}
+
+func (s Store) Load(puz *puzzle.PuzzleDefinition) (Progress, error) {
+	var progress Progress
+	data, err := os.ReadFile(s.path(puz.Hash()))
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, ErrNoProgress
+	} else if err != nil {
+		return progress, err
+	}
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return progress, err
+	}
+	if len(progress.State) != len(puz.Answer) {
+		return progress, ErrNoProgress
+	}
+	return progress, nil
+}
+
+func (s Store) Save(puz *puzzle.PuzzleDefinition, progress Progress) error {
+	progress.Hash = puz.Hash()
+	progress.Title = puz.Title
+	progress.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(progress.Hash), data, 0644)
+}
@@ -0,0 +1,30 @@
+package puzzle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Hash identifies a puzzle independently of the file it was loaded from and
+// of its fill. It covers the metadata, the layout of black squares and the
+// clues, so it is unchanged by unlocking a scrambled solution.
+func (puz *PuzzleDefinition) Hash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%dx%d\x00", puz.Title, puz.Author, puz.NumRows, puz.NumCols)
+	var layout strings.Builder
+	for i := range len(puz.Answer) {
+		if puz.Answer[i] == '.' {
+			layout.WriteByte('.')
+		} else {
+			layout.WriteByte('-')
+		}
+	}
+	h.Write([]byte(layout.String()))
+	for _, clue := range slices.Concat(puz.AcrossClues, puz.DownClues) {
+		fmt.Fprintf(h, "\x00%d%s:%s", clue.Num, clue.Direction, clue.Clue)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
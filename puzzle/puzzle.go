@@ -2,6 +2,7 @@ package puzzle
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -18,24 +19,37 @@ type PuzzleDefinition struct {
 	DownClues    []*Clue
 	Answer       string
 	CurrentState string
+	Locked       bool
+	LockChecksum uint16
+	// Circles marks circled squares by index into Answer. It is nil when
+	// the puzzle has no circles.
+	Circles []bool
+	// Rebus holds the full answer of every square that takes more than one
+	// letter, keyed by index into Answer. Answer itself only has the first.
+	Rebus map[int]string
 }
 
 type Clue struct {
-	Num      int
-	StartRow int
-	StartCol int
-	EndRow   int
-	EndCol   int
-	Clue     string
-	Answer   string
+	Num        int
+	Direction  Direction
+	StartRow   int
+	StartCol   int
+	EndRow     int
+	EndCol     int
+	Clue       string
+	Answer     string
+	References []*Clue
 }
 
-var Clues map[int]Clue
-var AcrossClues []*Clue
-var DownClues []*Clue
+type Direction int
+
+const (
+	Across Direction = iota
+	Down
+)
 
 func (puz *PuzzleDefinition) AssignClues(clues []string) {
-	Clues = make(map[int]Clue)
+	var acrossClues, downClues []*Clue
 	clueNum := 1
 	clueIndex := 0
 	for i := 0; i < len(puz.Answer); i++ {
@@ -56,32 +70,68 @@ func (puz *PuzzleDefinition) AssignClues(clues []string) {
 		if isAcrossClueStart {
 			clue := puz.parseClue(clueNum, row, col, true)
 			clue.Clue = clues[clueIndex]
-			AcrossClues = append(AcrossClues, clue)
+			acrossClues = append(acrossClues, clue)
 			clueIndex++
 		}
 
 		if isDownClueStart {
 			clue := puz.parseClue(clueNum, row, col, false)
 			clue.Clue = clues[clueIndex]
-			DownClues = append(DownClues, clue)
+			downClues = append(downClues, clue)
 			clueIndex++
 		}
 		clueNum++
 	}
-	puz.AcrossClues = AcrossClues
-	puz.DownClues = DownClues
+	puz.AcrossClues = acrossClues
+	puz.DownClues = downClues
+	puz.LinkReferences()
+}
+
+// CountEntries counts the entries the grid in Answer has in each direction,
+// which is how many clues AssignClues expects.
+func (puz *PuzzleDefinition) CountEntries() (across, down int) {
+	for i := 0; i < len(puz.Answer); i++ {
+		if puz.Answer[i] == '.' {
+			continue
+		}
+		row := i / puz.NumCols
+		col := i % puz.NumCols
+		if col == 0 || puz.Answer[i-1] == '.' {
+			across++
+		}
+		if row == 0 || puz.Answer[i-puz.NumCols] == '.' {
+			down++
+		}
+	}
+	return across, down
+}
+
+// AssignNumberedClues is AssignClues for formats that key clue text by number
+// and direction rather than relying on the order of the clues.
+func (puz *PuzzleDefinition) AssignNumberedClues(across, down map[int]string) {
+	puz.AssignClues(make([]string, len(puz.Answer)*2))
+	for _, clue := range puz.AcrossClues {
+		clue.Clue = across[clue.Num]
+	}
+	for _, clue := range puz.DownClues {
+		clue.Clue = down[clue.Num]
+	}
+	puz.NumClues = len(puz.AcrossClues) + len(puz.DownClues)
+	puz.LinkReferences()
 }
 
 func (p PuzzleDefinition) parseClue(clueNumber, startRow, startCol int, isAcrossClue bool) *Clue {
 	clue := Clue{
-		Num:      clueNumber,
-		StartRow: startRow,
-		StartCol: startCol,
+		Num:       clueNumber,
+		Direction: Down,
+		StartRow:  startRow,
+		StartCol:  startCol,
 	}
 	row, col := startRow, startCol
 	var sb strings.Builder
 	var dr, dc int
 	if isAcrossClue {
+		clue.Direction = Across
 		dr, dc = 0, 1
 	} else {
 		dr, dc = 1, 0
@@ -103,6 +153,20 @@ func (p PuzzleDefinition) parseClue(clueNumber, startRow, startCol int, isAcross
 	return &clue
 }
 
+// UpdateClueAnswers re-reads the answer to every clue from Answer, for when
+// the solution changes after the clues were assigned.
+func (puz *PuzzleDefinition) UpdateClueAnswers() {
+	for _, clue := range slices.Concat(puz.AcrossClues, puz.DownClues) {
+		var sb strings.Builder
+		for row := clue.StartRow; row <= clue.EndRow; row++ {
+			for col := clue.StartCol; col <= clue.EndCol; col++ {
+				sb.WriteByte(puz.Answer[row*puz.NumCols+col])
+			}
+		}
+		clue.Answer = sb.String()
+	}
+}
+
 func (p PuzzleDefinition) isVisitable(row, col int) bool {
 	return row < p.NumRows &&
 		col < p.NumCols &&
@@ -123,6 +187,13 @@ func (p PuzzleDefinition) String() string {
 	return output
 }
 
+func (d Direction) String() string {
+	if d == Down {
+		return "Down"
+	}
+	return "Across"
+}
+
 func (c Clue) String() string {
 	return fmt.Sprintf("Clue{num: %d, r: %d, c:%d, endr: %d, endc: %d, Clue: %s, Answer: %s}",
 		c.Num,
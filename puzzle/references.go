@@ -0,0 +1,43 @@
+package puzzle
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// crossReferencePattern matches references such as "17-Across",
+// "See 5 Down" and "23-, 31- and 45-Down".
+var crossReferencePattern = regexp.MustCompile(`(?i)((?:\b\d+-?\s*(?:,|and|&)?\s*)+)-?\s*\b(across|down)\b`)
+var clueNumberPattern = regexp.MustCompile(`\d+`)
+
+// LinkReferences parses cross-references out of the text of every clue and
+// records the clues they point to in References.
+func (puz *PuzzleDefinition) LinkReferences() {
+	for _, clue := range slices.Concat(puz.AcrossClues, puz.DownClues) {
+		clue.References = nil
+		for _, match := range crossReferencePattern.FindAllStringSubmatch(clue.Clue, -1) {
+			clues := puz.AcrossClues
+			if strings.EqualFold(match[2], "down") {
+				clues = puz.DownClues
+			}
+			for _, numText := range clueNumberPattern.FindAllString(match[1], -1) {
+				num, _ := strconv.Atoi(numText)
+				referenced := findClue(clues, num)
+				if referenced != nil && referenced != clue && !slices.Contains(clue.References, referenced) {
+					clue.References = append(clue.References, referenced)
+				}
+			}
+		}
+	}
+}
+
+func findClue(clues []*Clue, num int) *Clue {
+	for _, clue := range clues {
+		if clue.Num == num {
+			return clue
+		}
+	}
+	return nil
+}
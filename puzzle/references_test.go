@@ -0,0 +1,67 @@
+package puzzle
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestLinkReferences(t *testing.T) {
+	tests := []struct {
+		clue string
+		want []string
+	}{
+		{"See 17-Across", []string{"17 Across"}},
+		{"With 17- and 23-Down, a famous quote", []string{"17 Down", "23 Down"}},
+		{"Theme of 1, 5 and 9 Across", []string{"1 Across", "5 Across", "9 Across"}},
+		{"23-, 17- & 9-Across", []string{"23 Across", "17 Across", "9 Across"}},
+		{"see 17 across or 23 DOWN", []string{"17 Across", "23 Down"}},
+		{"Partner of 17-Across and 17-Across", []string{"17 Across"}},
+		{"See 99-Across", nil},
+		{"Like 42-Down, but not 5-Across", []string{"5 Across"}},
+		{"This clue, 3-Across", nil},
+		{"Across the street", nil},
+		{"Route 66", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.clue, func(t *testing.T) {
+			var puz PuzzleDefinition
+			for _, num := range []int{1, 3, 5, 9, 17, 23} {
+				puz.AcrossClues = append(puz.AcrossClues, &Clue{Num: num, Direction: Across})
+			}
+			for _, num := range []int{1, 17, 23} {
+				puz.DownClues = append(puz.DownClues, &Clue{Num: num, Direction: Down})
+			}
+			clue := puz.AcrossClues[1]
+			clue.Clue = test.clue
+
+			puz.LinkReferences()
+
+			var got []string
+			for _, referenced := range clue.References {
+				got = append(got, fmt.Sprintf("%d %s", referenced.Num, referenced.Direction))
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("references of %q = %q, want %q", test.clue, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLinkReferencesClearsStaleLinks(t *testing.T) {
+	puz := PuzzleDefinition{
+		AcrossClues: []*Clue{{Num: 1, Direction: Across, Clue: "See 2-Down"}},
+		DownClues:   []*Clue{{Num: 2, Direction: Down}},
+	}
+	puz.LinkReferences()
+	if len(puz.AcrossClues[0].References) != 1 {
+		t.Fatalf("got %d references, want 1", len(puz.AcrossClues[0].References))
+	}
+
+	puz.AcrossClues[0].Clue = "No longer a reference"
+	puz.LinkReferences()
+	if len(puz.AcrossClues[0].References) != 0 {
+		t.Errorf("got %d references after the clue changed, want 0", len(puz.AcrossClues[0].References))
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tylerwgrass/cruciterm/replay"
+	"github.com/tylerwgrass/cruciterm/solver"
+)
+
+func runReplay(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	var options solverOptions
+	options.registerAppearance(fs)
+	speed := fs.Float64("speed", 1, "playback speed, e.g. 0.5, 2 or 8")
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+
+	cfg, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if err := options.setup(cfg); err != nil {
+		return fail(cmd, err)
+	}
+
+	puz, err := loadPuzzle(fs.Arg(0))
+	if err != nil {
+		return fail(cmd, err)
+	}
+	log, err := replay.DefaultStore().Load(&puz)
+	if errors.Is(err, replay.ErrNoReplay) {
+		return fail(cmd, fmt.Errorf("%s: %w", fs.Arg(0), err))
+	} else if err != nil {
+		return fail(cmd, err)
+	}
+	if err := solver.RunReplay(&puz, log, *speed); err != nil {
+		return fail(cmd, err)
+	}
+	return exitOK
+}
@@ -0,0 +1,86 @@
+// Package replay records how a puzzle was solved, move by move, so that the
+// solve can be played back later.
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/config"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+var ErrNoReplay = errors.New("no replay recorded for puzzle")
+
+// Event is a change to the grid at a point in the solve. Events with an
+// empty Fill only move the cursor.
+type Event struct {
+	At   time.Duration `json:"t"`
+	Row  int           `json:"r,omitempty"`
+	Col  int           `json:"c,omitempty"`
+	Fill string        `json:"f,omitempty"`
+	// Down is the cursor direction after a move.
+	Down bool `json:"d,omitempty"`
+}
+
+// Log is every event of a solve, in order, starting from Initial.
+type Log struct {
+	Hash    string  `json:"hash"`
+	Title   string  `json:"title"`
+	Initial string  `json:"initial"`
+	Events  []Event `json:"events"`
+}
+
+// Duration is the time of the last event.
+func (l Log) Duration() time.Duration {
+	if len(l.Events) == 0 {
+		return 0
+	}
+	return l.Events[len(l.Events)-1].At
+}
+
+// Store keeps one replay per puzzle alongside its saved progress.
+type Store struct {
+	Dir string
+}
+
+func DefaultStore() Store {
+	return Store{Dir: filepath.Join(config.StateDir(), "progress")}
+}
+
+func (s Store) path(hash string) string {
+	return filepath.Join(s.Dir, hash+".replay.json")
+}
+
+func (s Store) Load(puz *puzzle.PuzzleDefinition) (Log, error) {
+	var log Log
+	data, err := os.ReadFile(s.path(puz.Hash()))
+	if errors.Is(err, os.ErrNotExist) {
+		return log, ErrNoReplay
+	} else if err != nil {
+		return log, err
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		return log, err
+	}
+	if len(log.Initial) != len(puz.Answer) {
+		return log, ErrNoReplay
+	}
+	return log, nil
+}
+
+func (s Store) Save(puz *puzzle.PuzzleDefinition, log Log) error {
+	log.Hash = puz.Hash()
+	log.Title = puz.Title
+	data, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(log.Hash), data, 0644)
+}
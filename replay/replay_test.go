@@ -0,0 +1,48 @@
+package replay
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+func TestStore(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+	puz := &puzzle.PuzzleDefinition{Title: "Replayed", NumRows: 2, NumCols: 2, Answer: "ABCD"}
+	if _, err := store.Load(puz); !errors.Is(err, ErrNoReplay) {
+		t.Fatalf("loading before saving: err = %v, want ErrNoReplay", err)
+	}
+
+	log := Log{Initial: "----", Events: []Event{
+		{At: 0, Row: 0, Col: 0},
+		{At: time.Second, Row: 0, Col: 0, Fill: "A"},
+		{At: 2 * time.Second, Row: 1, Col: 0, Down: true},
+	}}
+	if err := store.Save(puz, log); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := store.Load(puz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Hash != puz.Hash() || loaded.Title != "Replayed" || loaded.Initial != log.Initial || !slices.Equal(loaded.Events, log.Events) {
+		t.Errorf("loaded %+v, want %+v", loaded, log)
+	}
+	if loaded.Duration() != 2*time.Second {
+		t.Errorf("duration is %v, want 2s", loaded.Duration())
+	}
+	if (Log{}).Duration() != 0 {
+		t.Error("an empty log has a duration")
+	}
+
+	// A replay from a different grid isn't played back on this one.
+	if err := store.Save(puz, Log{Initial: "---"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(puz); !errors.Is(err, ErrNoReplay) {
+		t.Errorf("loading a replay of the wrong size: err = %v, want ErrNoReplay", err)
+	}
+}
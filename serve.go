@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"syscall"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/tylerwgrass/cruciterm/config"
+	"github.com/tylerwgrass/cruciterm/logger"
+	"github.com/tylerwgrass/cruciterm/solver"
+)
+
+const defaultServeAddress = ":2222"
+
+func runServe(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	var options solverOptions
+	options.register(fs)
+	listen := fs.String("listen", defaultServeAddress, "address to accept SSH connections on")
+	hostKey := fs.String("host-key", filepath.Join(config.StateDir(), "ssh_host_ed25519"), "SSH host key, created if missing")
+	authorizedKeys := fs.String("authorized-keys", "", "only let in the public keys listed in this authorized_keys file")
+	if code, ok := parseFlags(fs, args, -1); !ok {
+		return code
+	}
+
+	cfg, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if err := options.setup(cfg); err != nil {
+		return fail(cmd, err)
+	}
+	options.inLibrary = true
+
+	dirs := libraryDirs(cfg, fs.Args())
+	var sessions sync.WaitGroup
+	server, err := newSSHServer(cmd, dirs, options, *hostKey, *authorizedKeys, &sessions)
+	if err != nil {
+		return fail(cmd, err)
+	}
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fail(cmd, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	logger.Info("serving over ssh", "addr", listener.Addr(), "dirs", dirs)
+	fmt.Printf("Serving puzzles over SSH on %s\n", listener.Addr())
+
+	select {
+	case err = <-served:
+	case <-ctx.Done():
+		// Closing every connection ends each solver as if the user had quit,
+		// so their progress is still saved.
+		err = server.Close()
+	}
+	sessions.Wait()
+	if err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return fail(cmd, err)
+	}
+	return exitOK
+}
+
+// newSSHServer serves the library of puzzles in dirs to anyone holding one
+// of authorizedKeys, or any key if that is empty. sessions counts the
+// sessions still running.
+func newSSHServer(cmd *command, dirs []string, options solverOptions, hostKey, authorizedKeys string, sessions *sync.WaitGroup) (*ssh.Server, error) {
+	// Progress is kept per public key, so everyone needs one.
+	auth := wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true })
+	if authorizedKeys != "" {
+		auth = wish.WithAuthorizedKeys(authorizedKeys)
+	}
+	return wish.NewServer(
+		wish.WithHostKeyPath(hostKey),
+		auth,
+		wish.WithMiddleware(func(ssh.Handler) ssh.Handler {
+			return func(s ssh.Session) {
+				sessions.Add(1)
+				defer sessions.Done()
+				// A crash ends only the session it happened in.
+				defer func() {
+					if r := recover(); r != nil {
+						logger.Error("ssh session crashed", "user", s.User(), "err", r, "stack", string(debug.Stack()))
+						wish.Errorln(s, "cruciterm crashed, sorry")
+						s.Exit(exitFailure)
+					}
+				}()
+				s.Exit(serveSession(cmd, s, dirs, options))
+			}
+		}),
+	)
+}
+
+// serveSession runs the library in an SSH session, keeping the user's
+// progress and history apart from everyone else's.
+func serveSession(cmd *command, s ssh.Session, dirs []string, options solverOptions) int {
+	pty, windows, ok := s.Pty()
+	if !ok {
+		wish.Errorln(s, "cruciterm needs a terminal, try connecting with ssh -t")
+		return exitUsage
+	}
+	if s.PublicKey() == nil {
+		wish.Errorln(s, "cruciterm needs a public key to keep your progress")
+		return exitUsage
+	}
+	user := userKey(s.PublicKey())
+	logger.Info("ssh session started", "user", s.User(), "key", user, "remote", s.RemoteAddr())
+	defer logger.Info("ssh session ended", "user", s.User(), "key", user)
+
+	environ := append(s.Environ(), "TERM="+pty.Term)
+	term := solver.NewTerminal(s.Context(), s, s, environ, pty.Window.Width, pty.Window.Height)
+	go func() {
+		for window := range windows {
+			term.Resize(window.Width, window.Height)
+		}
+	}()
+
+	options.stateDir = filepath.Join(config.StateDir(), "users", user)
+	// The puzzles are shared, so only the user's own state is written to.
+	options.readOnly = true
+	// Changing a preference in one session leaves everyone else's alone.
+	options.preferences = maps.Clone(options.preferences)
+	options.terminal = term
+	return browseLibrary(cmd, dirs, options)
+}
+
+// userKey names the state directory of whoever holds key.
+func userKey(key ssh.PublicKey) string {
+	sum := sha256.Sum256(key.Marshal())
+	return hex.EncodeToString(sum[:16])
+}
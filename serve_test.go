@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/config"
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/progress"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// screen collects what a session has shown so far.
+type screen struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+// waitFor waits for text to be shown after the first from bytes, and
+// returns how much has been shown.
+func (s *screen) waitFor(t *testing.T, from int, text string) int {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		s.mu.Lock()
+		shown := s.buf.String()
+		s.mu.Unlock()
+		if i := strings.Index(shown[from:], text); i >= 0 {
+			return from + i + len(text)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q, got %q", text, shown[from:])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newSigner(t *testing.T) gossh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := gossh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// solveOverSSH opens the only puzzle in the library as whoever holds
+// signer, types letter into the first square and quits.
+func solveOverSSH(t *testing.T, addr string, signer gossh.Signer, letter string) {
+	t.Helper()
+	client, err := gossh.Dial("tcp", addr, &gossh.ClientConfig{
+		User:            "solver",
+		Auth:            []gossh.AuthMethod{gossh.PublicKeys(signer)},
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	if err := session.RequestPty("xterm-256color", 40, 120, gossh.TerminalModes{}); err != nil {
+		t.Fatal(err)
+	}
+	input, err := session.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out screen
+	session.Stdout = &out
+	if err := session.Shell(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys typed while one program exits and the next starts would be lost,
+	// so wait for what only the library shows, and then the solver.
+	shown := out.waitFor(t, 0, "open puzzle")
+	input.Write([]byte("\r"))
+	shown = out.waitFor(t, shown, "Feline")
+	// Keys are handled in order, so the letter is in before quitting.
+	input.Write([]byte(letter + "\x03"))
+	out.waitFor(t, shown, "open puzzle")
+	input.Write([]byte{3})
+	if err := session.Wait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServeKeepsProgressPerKey(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	puz := &puzzle.PuzzleDefinition{
+		Title:        "Loopback",
+		NumRows:      3,
+		NumCols:      3,
+		Answer:       "CAT" + "ARE" + "TEN",
+		CurrentState: strings.Repeat("-", 9),
+	}
+	puz.AssignClues([]string{"Feline", "Exist", "Number", "Feline", "Exist", "Number"})
+	puz.NumClues = len(puz.AcrossClues) + len(puz.DownClues)
+	path := filepath.Join(dir, "loopback.json")
+	if err := loader.SaveFile(path, puz); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loader.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var options solverOptions
+	if err := options.setup(config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	options.inLibrary = true
+	var sessions sync.WaitGroup
+	cmd := &command{name: "serve"}
+	server, err := newSSHServer(cmd, []string{dir}, options, filepath.Join(t.TempDir(), "host_key"), "", &sessions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(listener)
+	t.Cleanup(func() {
+		server.Close()
+		sessions.Wait()
+	})
+
+	alice, bob := newSigner(t), newSigner(t)
+	state := func(signer gossh.Signer) string {
+		t.Helper()
+		store := progress.Store{Dir: filepath.Join(config.StateDir(), "users", userKey(signer.PublicKey()), "progress")}
+		saved, err := store.Load(&loaded)
+		if errors.Is(err, progress.ErrNoProgress) {
+			return ""
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		return saved.State
+	}
+
+	solveOverSSH(t, listener.Addr().String(), alice, "c")
+	if got := state(alice); got != "C--------" {
+		t.Errorf("alice's progress is %q, want %q", got, "C--------")
+	}
+	if got := state(bob); got != "" {
+		t.Errorf("bob has progress %q before connecting", got)
+	}
+
+	solveOverSSH(t, listener.Addr().String(), bob, "x")
+	if got := state(bob); got != "X--------" {
+		t.Errorf("bob's progress is %q, want %q", got, "X--------")
+	}
+	if got := state(alice); got != "C--------" {
+		t.Errorf("bob's solve changed alice's progress to %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(config.StateDir(), "progress")); !os.IsNotExist(err) {
+		t.Errorf("progress was kept outside the users' directories: %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/config"
+	"github.com/tylerwgrass/cruciterm/logger"
+	"github.com/tylerwgrass/cruciterm/preferences"
+	"github.com/tylerwgrass/cruciterm/progress"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/replay"
+	"github.com/tylerwgrass/cruciterm/solver"
+	"github.com/tylerwgrass/cruciterm/stats"
+	"github.com/tylerwgrass/cruciterm/theme"
+	"github.com/tylerwgrass/cruciterm/web"
+)
+
+// solverOptions are the flags shared by the commands that open the solver.
+type solverOptions struct {
+	themeID    string
+	keymapPath string
+	noTimer    bool
+	// inLibrary is set when solving from the library rather than by flag.
+	inLibrary bool
+	// stateDir overrides where progress and history are kept, e.g. for each
+	// SSH user, and terminal where the solver runs.
+	stateDir string
+	terminal *solver.Terminal
+	// webAddress shares the solve in the browser when set.
+	webAddress string
+	webEdits   bool
+	// preferences start out as the config's, and each solver's are its own.
+	preferences preferences.Preferences
+	// readOnly leaves the puzzle files alone, e.g. when they are served to
+	// other users.
+	readOnly bool
+}
+
+// userState is where one solver's progress, replays and history are kept.
+type userState struct {
+	progress progress.Store
+	replays  replay.Store
+	history  stats.Store
+}
+
+// userStateIn keeps everything under dir, or the state directory if dir is
+// empty.
+func userStateIn(dir string) userState {
+	if dir == "" {
+		dir = config.StateDir()
+	}
+	return userState{
+		progress: progress.Store{Dir: filepath.Join(dir, "progress")},
+		replays:  replay.Store{Dir: filepath.Join(dir, "progress")},
+		history:  stats.Store{Path: filepath.Join(dir, "stats.jsonl")},
+	}
+}
+
+// saveProgress saves how the puzzle was left and the replay of the solve.
+func (u userState) saveProgress(puz *puzzle.PuzzleDefinition, result solver.Result) error {
+	err := u.progress.Save(puz, progress.Progress{
+		State:        result.State,
+		Elapsed:      result.Elapsed,
+		Solved:       result.Solved,
+		Checks:       result.Checks,
+		Reveals:      result.Reveals,
+		Corrections:  result.Corrections,
+		LettersTyped: result.LettersTyped,
+	})
+	if err != nil {
+		return fmt.Errorf("could not save progress: %w", err)
+	}
+	if err := u.replays.Save(puz, result.Replay); err != nil {
+		return fmt.Errorf("could not save replay: %w", err)
+	}
+	return nil
+}
+
+func (s *solverOptions) register(fs *flag.FlagSet) {
+	s.registerAppearance(fs)
+	fs.BoolVar(&s.noTimer, "no-timer", false, "hide the solving timer")
+}
+
+// registerAppearance adds only the theme and key binding flags.
+func (s *solverOptions) registerAppearance(fs *flag.FlagSet) {
+	fs.StringVar(&s.themeID, "theme", "", "color theme to use (default \""+theme.Default()+"\")")
+	fs.StringVar(&s.keymapPath, "keymap", "", "JSON file overriding key bindings")
+}
+
+// registerWeb adds the flags for following the solve in a browser.
+func (s *solverOptions) registerWeb(fs *flag.FlagSet) {
+	fs.StringVar(&s.webAddress, "web", "", "show the solve in the browser at this address, e.g. :8080")
+	fs.BoolVar(&s.webEdits, "web-edits", false, "let people watching in the browser fill in squares too")
+}
+
+// setup applies the theme and key bindings, falling back to the config,
+// and reads the preferences from the config.
+func (s *solverOptions) setup(cfg config.Config) error {
+	var err error
+	if s.preferences, err = configPreferences(cfg); err != nil {
+		return err
+	}
+	theme.Init()
+	if s.themeID == "" {
+		s.themeID = cfg.Theme
+	}
+	if s.themeID != "" {
+		if err := theme.SetTint(s.themeID); err != nil {
+			return err
+		}
+	}
+	if s.keymapPath == "" {
+		s.keymapPath = cfg.KeyMap
+	}
+	if s.keymapPath != "" {
+		return solver.LoadKeyMap(s.keymapPath)
+	}
+	return nil
+}
+
+func runSolve(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	var options solverOptions
+	options.register(fs)
+	options.registerWeb(fs)
+	resume := fs.Bool("resume", false, "continue from saved progress instead of the fill stored in the file")
+	if code, ok := parseFlags(fs, args, -1); !ok {
+		return code
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	if options.webAddress != "" && fs.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "cruciterm %s: -web can only show one puzzle\n", cmd.name)
+		return exitUsage
+	}
+
+	cfg, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if err := options.setup(cfg); err != nil {
+		return fail(cmd, err)
+	}
+	return solvePuzzles(cmd, fs.Args(), *resume, options)
+}
+
+// solvePuzzles opens the puzzles at paths in the solver, one tab each, and
+// saves everyone's progress and replay once it exits. Puzzles finished along
+// the way are added to the solve history.
+func solvePuzzles(cmd *command, paths []string, resume bool, options solverOptions) int {
+	state := userStateIn(options.stateDir)
+	puzzles := make([]*puzzle.PuzzleDefinition, len(paths))
+	sessions := make([]solver.Session, len(paths))
+	for i, path := range paths {
+		puz, err := loadPuzzle(path)
+		if err != nil {
+			return fail(cmd, err)
+		}
+		puzzles[i] = &puz
+		sessions[i] = solver.Session{Puzzle: &puz}
+		// Remote solvers mustn't write to the puzzle files everyone shares.
+		if options.terminal == nil {
+			sessions[i].Path = path
+		}
+		if !resume {
+			continue
+		}
+		saved, err := state.progress.Load(&puz)
+		if err == nil {
+			logger.Info("resuming saved progress", "hash", saved.Hash, "elapsed", saved.Elapsed)
+			puz.CurrentState = saved.State
+			sessions[i].Elapsed = saved.Elapsed
+			sessions[i].Checks, sessions[i].Reveals = saved.Checks, saved.Reveals
+			sessions[i].Corrections, sessions[i].LettersTyped = saved.Corrections, saved.LettersTyped
+			if log, err := state.replays.Load(&puz); err == nil {
+				sessions[i].Replay = log
+			} else if !errors.Is(err, replay.ErrNoReplay) {
+				logger.Warn("could not load replay", "err", err)
+			}
+		} else if !errors.Is(err, progress.ErrNoProgress) {
+			return fail(cmd, err)
+		}
+	}
+
+	if options.webAddress != "" {
+		server, err := web.Listen(options.webAddress, puzzles[0], options.webEdits)
+		if err != nil {
+			return fail(cmd, err)
+		}
+		defer server.Close()
+		go func() {
+			if err := server.Serve(); err != nil {
+				logger.Error("web server stopped", "err", err)
+			}
+		}()
+		logger.Info("showing puzzle in the browser", "url", server.URL(), "edits", options.webEdits)
+		sessions[0].Web = server
+	}
+
+	solves, err := state.history.Load()
+	if err != nil {
+		logger.Warn("could not load solve history", "err", err)
+	}
+	results, err := solver.Run(sessions, solver.Options{
+		HideTimer:   options.noTimer,
+		History:     stats.Summarize(solves, time.Now()),
+		InLibrary:   options.inLibrary,
+		Terminal:    options.terminal,
+		Preferences: options.preferences,
+		ReadOnly:    options.readOnly,
+	})
+	if err != nil {
+		return fail(cmd, err)
+	}
+
+	code := exitOK
+	for i, result := range results {
+		logger.Info("solver exited", "title", puzzles[i].Title, "solved", result.Solved, "elapsed", result.Elapsed)
+		if result.NewlySolved {
+			solve := stats.NewSolve(puzzles[i], result.Elapsed, result.Checks, result.Reveals)
+			if err := state.history.Record(solve); err != nil {
+				logger.Error("could not record solve", "err", err)
+			}
+		}
+		if err := state.saveProgress(puzzles[i], result); err != nil {
+			logger.Error("could not save solve", "path", paths[i], "err", err)
+			fmt.Fprintf(os.Stderr, "cruciterm %s: %s: %v\n", cmd.name, paths[i], err)
+			code = exitFailure
+		}
+	}
+	return code
+}
@@ -0,0 +1,322 @@
+package solver
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/wordlist"
+)
+
+var ErrNoFill = errors.New("no fill from the word list fits the grid")
+var ErrFillTimeout = errors.New("ran out of time before finding a fill")
+
+// FillOptions tune Autofill.
+type FillOptions struct {
+	// MinScore leaves out words scored lower.
+	MinScore int
+	// Randomness, from 0 to 1, jitters each word's score by up to that share
+	// of the list's range of scores, so that fills vary. At 0 the best words
+	// are always tried first.
+	Randomness float64
+	// Seed makes random fills repeatable. 0 picks a seed at random.
+	Seed uint64
+	// TimeLimit gives up on the fill after this long when set.
+	TimeLimit time.Duration
+}
+
+// fillSlot is an entry to fill. Each of its squares may be crossed by another
+// slot, at crossings[i].
+type fillSlot struct {
+	clue      *puzzle.Clue
+	cells     []*Cell
+	crossings []fillCrossing
+	// words are the candidates that still fit, best first.
+	words  []string
+	filled bool
+}
+
+type fillCrossing struct {
+	// slot is -1 when the square isn't part of another slot.
+	slot  int
+	index int
+}
+
+// fillAssignment remembers what placing a word changed, to take it back.
+type fillAssignment struct {
+	cells   []*Cell
+	domains []fillDomain
+}
+
+type fillDomain struct {
+	slot  *fillSlot
+	words []string
+}
+
+type filler struct {
+	ctx   context.Context
+	slots []*fillSlot
+	used  map[string]bool
+	steps int
+	err   error
+}
+
+// Autofill fills every empty square of grid with words from words, so that
+// every entry of two or more letters is in the list and no word is used
+// twice. Letters already in the grid are kept, and the grid is left as it was
+// if no fill is found.
+//
+// Entries are filled most constrained first: each word placed narrows the
+// candidates of the entries crossing it, and a word that leaves a crossing
+// entry without candidates is taken back straight away.
+func Autofill(ctx context.Context, grid NavigationGrid, words *wordlist.List, options FillOptions) error {
+	if options.TimeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.TimeLimit)
+		defer cancel()
+	}
+	f := &filler{ctx: ctx, used: make(map[string]bool)}
+
+	// Find the slots through the clues each square belongs to.
+	slotOf := make(map[*puzzle.Clue]int)
+	positions := make(map[*Cell][]fillCrossing)
+	for row := range grid {
+		for col := range grid[row] {
+			cell := &grid[row][col]
+			if cell.content == "." {
+				continue
+			}
+			for _, clue := range []*puzzle.Clue{cell.acrossClue, cell.downClue} {
+				if clue == nil || clueLength(clue) < 2 {
+					continue
+				}
+				i, ok := slotOf[clue]
+				if !ok {
+					i = len(f.slots)
+					slotOf[clue] = i
+					f.slots = append(f.slots, &fillSlot{clue: clue})
+				}
+				positions[cell] = append(positions[cell], fillCrossing{slot: i, index: len(f.slots[i].cells)})
+				f.slots[i].cells = append(f.slots[i].cells, cell)
+			}
+		}
+	}
+	for i, slot := range f.slots {
+		for _, cell := range slot.cells {
+			crossing := fillCrossing{slot: -1}
+			for _, position := range positions[cell] {
+				if position.slot != i {
+					crossing = position
+				}
+			}
+			slot.crossings = append(slot.crossings, crossing)
+		}
+	}
+
+	ranked := rankWords(words, options)
+	for _, slot := range f.slots {
+		pattern := slot.pattern()
+		if !strings.Contains(pattern, "-") {
+			slot.filled = true
+			f.used[pattern] = true
+			continue
+		}
+		for _, word := range ranked(len(pattern)) {
+			if fits(word, pattern) {
+				slot.words = append(slot.words, word)
+			}
+		}
+		if len(slot.words) == 0 {
+			return fmt.Errorf("%w: nothing fits %d %s (%s)", ErrNoFill,
+				slot.clue.Num, slot.clue.Direction, strings.ReplaceAll(pattern, "-", "_"))
+		}
+	}
+
+	if f.solve() {
+		return nil
+	}
+	if f.err != nil {
+		return f.err
+	}
+	return ErrNoFill
+}
+
+func (f *filler) solve() bool {
+	f.steps++
+	if f.steps%256 == 0 && f.ctx.Err() != nil {
+		f.err = f.ctx.Err()
+		if errors.Is(f.err, context.DeadlineExceeded) {
+			f.err = ErrFillTimeout
+		}
+		return false
+	}
+
+	var next *fillSlot
+	for _, slot := range f.slots {
+		if !slot.filled && (next == nil || len(slot.words) < len(next.words)) {
+			next = slot
+		}
+	}
+	if next == nil {
+		return true
+	}
+	for _, word := range next.words {
+		if f.used[word] {
+			continue
+		}
+		assignment, ok := f.assign(next, word)
+		if ok && f.solve() {
+			return true
+		}
+		f.unassign(next, word, assignment)
+		if f.err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// assign places word in slot and narrows the candidates of the slots crossing
+// it, reporting false if one of them is left without any.
+func (f *filler) assign(slot *fillSlot, word string) (fillAssignment, bool) {
+	var assignment fillAssignment
+	slot.filled = true
+	f.used[word] = true
+	for i, cell := range slot.cells {
+		if cell.content != "-" {
+			continue
+		}
+		cell.content = word[i : i+1]
+		assignment.cells = append(assignment.cells, cell)
+		crossing := slot.crossings[i]
+		if crossing.slot < 0 || f.slots[crossing.slot].filled {
+			continue
+		}
+		other := f.slots[crossing.slot]
+		assignment.domains = append(assignment.domains, fillDomain{slot: other, words: other.words})
+		var narrowed []string
+		for _, candidate := range other.words {
+			if candidate[crossing.index] == word[i] {
+				narrowed = append(narrowed, candidate)
+			}
+		}
+		other.words = narrowed
+		if len(narrowed) == 0 {
+			return assignment, false
+		}
+	}
+	return assignment, true
+}
+
+func (f *filler) unassign(slot *fillSlot, word string, assignment fillAssignment) {
+	for i := len(assignment.domains) - 1; i >= 0; i-- {
+		assignment.domains[i].slot.words = assignment.domains[i].words
+	}
+	for _, cell := range assignment.cells {
+		cell.content = "-"
+	}
+	slot.filled = false
+	delete(f.used, word)
+}
+
+func (s *fillSlot) pattern() string {
+	var sb strings.Builder
+	for _, cell := range s.cells {
+		sb.WriteString(cell.content)
+	}
+	return sb.String()
+}
+
+// fits reports whether word matches pattern, where "-" matches any letter.
+func fits(word, pattern string) bool {
+	for i := range len(pattern) {
+		if pattern[i] != '-' && pattern[i] != word[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func clueLength(clue *puzzle.Clue) int {
+	return clue.EndRow - clue.StartRow + clue.EndCol - clue.StartCol + 1
+}
+
+// rankWords orders the words of each length from the list by score, jittered
+// by options.Randomness, leaving out those scored below options.MinScore.
+func rankWords(words *wordlist.List, options FillOptions) func(length int) []string {
+	seed := options.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+	ranked := make(map[int][]string)
+	return func(length int) []string {
+		if cached, ok := ranked[length]; ok {
+			return cached
+		}
+		type candidate struct {
+			word  string
+			score float64
+		}
+		var candidates []candidate
+		entries := words.Words(length)
+		if len(entries) > 0 {
+			// Entries come best first.
+			spread := float64(max(entries[0].Score-entries[len(entries)-1].Score, 1))
+			for _, entry := range entries {
+				if entry.Score < options.MinScore {
+					continue
+				}
+				jitter := options.Randomness * spread * rng.Float64()
+				candidates = append(candidates, candidate{entry.Word, float64(entry.Score) + jitter})
+			}
+		}
+		slices.SortStableFunc(candidates, func(a, b candidate) int {
+			return cmp.Compare(b.score, a.score)
+		})
+		ranked[length] = make([]string, len(candidates))
+		for i, c := range candidates {
+			ranked[length][i] = c.word
+		}
+		return ranked[length]
+	}
+}
+
+// FillPuzzle fills the empty squares of puz's answer with Autofill and returns
+// the filled answer, leaving puz as it is.
+func FillPuzzle(ctx context.Context, puz *puzzle.PuzzleDefinition, words *wordlist.List, options FillOptions) (string, error) {
+	grid := make(NavigationGrid, puz.NumRows)
+	for row := range grid {
+		grid[row] = make([]Cell, puz.NumCols)
+		for col := range grid[row] {
+			grid[row][col].content = string(puz.Answer[row*puz.NumCols+col])
+		}
+	}
+	for _, clue := range puz.AcrossClues {
+		for col := clue.StartCol; col <= clue.EndCol; col++ {
+			grid[clue.StartRow][col].acrossClue = clue
+		}
+	}
+	for _, clue := range puz.DownClues {
+		for row := clue.StartRow; row <= clue.EndRow; row++ {
+			grid[row][clue.StartCol].downClue = clue
+		}
+	}
+
+	if err := Autofill(ctx, grid, words, options); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, row := range grid {
+		for _, cell := range row {
+			sb.WriteString(cell.content)
+		}
+	}
+	return sb.String(), nil
+}
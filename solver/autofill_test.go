@@ -0,0 +1,208 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/wordlist"
+)
+
+func parseWords(t *testing.T, lines ...string) *wordlist.List {
+	t.Helper()
+	words, err := wordlist.Parse(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return words
+}
+
+func TestFillPuzzle(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		rows    int
+		cols    int
+		words   []string
+		options FillOptions
+		want    string
+		err     error
+	}{
+		{
+			name:   "best words first",
+			answer: "--" + "--", rows: 2, cols: 2,
+			words: []string{"AB;90", "CD;80", "AC;70", "BD;60", "XY;50", "AX;40", "BY;30"},
+			want:  "AB" + "CD",
+		},
+		{
+			// XY is best, but nothing goes down from X, so AB is used
+			// instead.
+			name:   "crossings narrow the candidates",
+			answer: "--" + "--", rows: 2, cols: 2,
+			words: []string{"XY;99", "AB;90", "CD;80", "AC;70", "BD;60"},
+			want:  "AB" + "CD",
+		},
+		{
+			name:   "letters in the grid are kept",
+			answer: "-D" + "--", rows: 2, cols: 2,
+			words: []string{"AB;90", "CD;80", "AC;70", "BD;60", "ED;50", "EF;40", "DG;30", "FG;20"},
+			want:  "ED" + "FG",
+		},
+		{
+			name:   "no word twice",
+			answer: "--" + ".." + "--", rows: 3, cols: 2,
+			words: []string{"AB;90", "CD;10"},
+			want:  "AB" + ".." + "CD",
+		},
+		{
+			name:   "words in the grid count as used",
+			answer: "--" + ".." + "CD", rows: 3, cols: 2,
+			words: []string{"CD;90", "AB;10"},
+			want:  "AB" + ".." + "CD",
+		},
+		{
+			name:   "one word for two entries",
+			answer: "--" + ".." + "--", rows: 3, cols: 2,
+			words: []string{"AB"},
+			err:   ErrNoFill,
+		},
+		{
+			name:   "only the same word across and down",
+			answer: "--" + "--", rows: 2, cols: 2,
+			words: []string{"AB", "BA"},
+			err:   ErrNoFill,
+		},
+		{
+			name:   "nothing fits",
+			answer: "Q-" + "--", rows: 2, cols: 2,
+			words: []string{"AB", "CD", "AC", "BD"},
+			err:   ErrNoFill,
+		},
+		{
+			name:   "low scores left out",
+			answer: "--" + ".." + "--", rows: 3, cols: 2,
+			words:   []string{"AB;90", "CD;10", "EF;50"},
+			options: FillOptions{MinScore: 50},
+			want:    "AB" + ".." + "EF",
+		},
+	}
+	for _, test := range tests {
+		puz := newTestPuzzle(test.answer, test.rows, test.cols, "")
+		got, err := FillPuzzle(context.Background(), puz, parseWords(t, test.words...), test.options)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: err = %v, want %v", test.name, err, test.err)
+		}
+		if got != test.want {
+			t.Errorf("%s: filled %q, want %q", test.name, got, test.want)
+		}
+		if puz.Answer != test.answer {
+			t.Errorf("%s: changed the puzzle's answer to %q", test.name, puz.Answer)
+		}
+	}
+}
+
+func TestFillPuzzleRandomness(t *testing.T) {
+	puz := newTestPuzzle("---", 1, 3, "")
+	words := parseWords(t, "ONE;60", "TWO;59", "SIX;58", "TEN;57")
+	fill := func(options FillOptions) string {
+		t.Helper()
+		got, err := FillPuzzle(context.Background(), puz, words, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	if got := fill(FillOptions{Seed: 1}); got != "ONE" {
+		t.Errorf("without randomness filled %q, want the best word", got)
+	}
+	seen := make(map[string]bool)
+	for seed := range uint64(20) {
+		options := FillOptions{Randomness: 1, Seed: seed + 1}
+		got := fill(options)
+		if again := fill(options); again != got {
+			t.Errorf("seed %d filled %q, then %q", options.Seed, got, again)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("random fills were all %v", seen)
+	}
+}
+
+// binaryWords lists every word of length letters made of A and B.
+func binaryWords(length int) []string {
+	var words []string
+	for i := range 1 << length {
+		word := make([]byte, length)
+		for b := range word {
+			word[b] = 'A' + byte(i>>b&1)
+		}
+		words = append(words, string(word))
+	}
+	return words
+}
+
+func TestFillPuzzleStops(t *testing.T) {
+	// Ten entries from ten words can't be filled, and it takes a while to
+	// find that out.
+	puz := newTestPuzzle(strings.Repeat("-", 25), 5, 5, "")
+	words := parseWords(t, binaryWords(5)[:10]...)
+
+	if _, err := FillPuzzle(context.Background(), puz, words, FillOptions{}); !errors.Is(err, ErrNoFill) {
+		t.Fatalf("err = %v, want ErrNoFill", err)
+	}
+	if _, err := FillPuzzle(context.Background(), puz, words, FillOptions{TimeLimit: time.Nanosecond}); !errors.Is(err, ErrFillTimeout) {
+		t.Errorf("with a time limit: err = %v, want ErrFillTimeout", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FillPuzzle(ctx, puz, words, FillOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("when canceled: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestFillAssignNarrowsCrossings(t *testing.T) {
+	// A 2x2 grid: slots 0 and 1 go across, 2 and 3 down.
+	cells := []*Cell{{content: "-"}, {content: "-"}, {content: "-"}, {content: "-"}}
+	slot := func(a, b int, crossA, crossB fillCrossing, words ...string) *fillSlot {
+		return &fillSlot{cells: []*Cell{cells[a], cells[b]}, crossings: []fillCrossing{crossA, crossB}, words: words}
+	}
+	f := &filler{used: make(map[string]bool), slots: []*fillSlot{
+		slot(0, 1, fillCrossing{2, 0}, fillCrossing{3, 0}, "AB", "CD"),
+		slot(2, 3, fillCrossing{2, 1}, fillCrossing{3, 1}, "CD", "EF"),
+		slot(0, 2, fillCrossing{0, 0}, fillCrossing{1, 0}, "AC", "AE", "CE"),
+		slot(1, 3, fillCrossing{0, 1}, fillCrossing{1, 1}, "BD", "BF", "EF"),
+	}}
+	across, down, other := f.slots[0], f.slots[2], f.slots[3]
+
+	assignment, ok := f.assign(across, "AB")
+	if !ok {
+		t.Fatal("AB left a crossing without candidates")
+	}
+	if !slices.Equal(down.words, []string{"AC", "AE"}) || !slices.Equal(other.words, []string{"BD", "BF"}) {
+		t.Errorf("crossings narrowed to %q and %q", down.words, other.words)
+	}
+	if !f.used["AB"] || !across.filled || across.pattern() != "AB" {
+		t.Error("AB wasn't placed")
+	}
+	f.unassign(across, "AB", assignment)
+	if !slices.Equal(down.words, []string{"AC", "AE", "CE"}) || !slices.Equal(other.words, []string{"BD", "BF", "EF"}) {
+		t.Errorf("taking AB back left the crossings with %q and %q", down.words, other.words)
+	}
+	if f.used["AB"] || across.filled || across.pattern() != "--" {
+		t.Error("AB wasn't taken back")
+	}
+
+	// Nothing goes down from D.
+	assignment, ok = f.assign(across, "CD")
+	if ok {
+		t.Errorf("CD was placed, leaving %q down from D", other.words)
+	}
+	f.unassign(across, "CD", assignment)
+	if !slices.Equal(down.words, []string{"AC", "AE", "CE"}) || !slices.Equal(other.words, []string{"BD", "BF", "EF"}) || across.pattern() != "--" {
+		t.Error("taking back CD didn't undo it")
+	}
+}
@@ -0,0 +1,49 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+// renderClueBar shows the clue being solved along with its fill pattern, and
+// beneath it the crossing clue at the cursor.
+func renderClueBar(m gridModel, width int) string {
+	grid := m.navigator.grid
+	activeClue, crossClue := m.currentClues()
+	if m.navOrientation == Vertical {
+		activeClue, crossClue = crossClue, activeClue
+	}
+
+	barStyle := theme.Get().
+		Border(lipgloss.NormalBorder(), false, false, true, false).
+		BorderForeground(theme.Primary()).
+		Width(width).
+		Padding(0, 1)
+
+	active := renderClueBarLine(grid, activeClue, m.navOrientation,
+		theme.Get().Foreground(theme.Primary()).Bold(true),
+		theme.Get())
+	cross := renderClueBarLine(grid, crossClue, m.navOrientation.cross(),
+		theme.Get().Foreground(theme.Secondary()),
+		theme.Get().Foreground(theme.Muted()))
+	return barStyle.Render(lipgloss.JoinVertical(lipgloss.Left, active, cross))
+}
+
+func renderClueBarLine(grid *NavigationGrid, clue *puzzle.Clue, orientation Orientation, labelStyle, textStyle lipgloss.Style) string {
+	if clue == nil {
+		return ""
+	}
+	pattern := grid.fillPattern(clue)
+	label := fmt.Sprintf("%d%s", clue.Num, string(orientationName(orientation)[0]))
+	length := fmt.Sprintf(" (%d)", len(pattern))
+	patternWidth := puzzleSize(grid) + len(length) + 2
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		labelStyle.Width(6).Render(label),
+		theme.Get().Width(patternWidth).Render(
+			labelStyle.Render(pattern)+theme.Get().Foreground(theme.Muted()).Render(length)),
+		textStyle.Render(clue.Clue),
+	)
+}
@@ -2,7 +2,9 @@ package solver
 
 import (
 	"fmt"
+	"slices"
 
+	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/charmbracelet/lipgloss/v2/list"
@@ -11,23 +13,37 @@ import (
 )
 
 var NUM_SHOWN_CLUES int = 9
-var acrossClues []*puzzle.Clue
-var downClues []*puzzle.Clue
-var currentAcrossClue *puzzle.Clue
-var currentDownClue *puzzle.Clue
 
 type cluesModel struct {
 	acrossClues           []*puzzle.Clue
 	downClues             []*puzzle.Clue
 	activeClueOrientation Orientation
+	grid                  *NavigationGrid
+	focused               bool
+	numShown              int
+	selectedAcross        int
+	selectedDown          int
+	acrossOffset          int
+	downOffset            int
+	currentAcrossClue     *puzzle.Clue
+	currentDownClue       *puzzle.Clue
+	solvingOrientation    Orientation
 }
 
-func initCluesModel(puz *puzzle.PuzzleDefinition) cluesModel {
-	acrossClues, downClues = puz.AcrossClues, puz.DownClues
+// clueSelectedMsg is sent when a clue is chosen from the clue list so the
+// grid can move its cursor to it.
+type clueSelectedMsg struct {
+	clue        *puzzle.Clue
+	orientation Orientation
+}
+
+func initCluesModel(puz *puzzle.PuzzleDefinition, grid *NavigationGrid) cluesModel {
 	return cluesModel{
 		acrossClues:           puz.AcrossClues,
 		downClues:             puz.DownClues,
 		activeClueOrientation: Horizontal,
+		grid:                  grid,
+		numShown:              max(NUM_SHOWN_CLUES, puz.NumRows),
 	}
 }
 
@@ -36,7 +52,92 @@ func (m cluesModel) Init() tea.Cmd {
 }
 
 func (m cluesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	return nil, nil
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if !m.focused {
+			break
+		}
+		switch {
+		case key.Matches(msg, keys.Back):
+			m.focused = false
+		case key.Matches(msg, keys.Up):
+			m.moveSelection(-1)
+		case key.Matches(msg, keys.Down):
+			m.moveSelection(1)
+		case key.Matches(msg, keys.PageUp):
+			m.moveSelection(-m.numShown)
+		case key.Matches(msg, keys.PageDown):
+			m.moveSelection(m.numShown)
+		case key.Matches(msg, keys.ListTop):
+			m.moveSelection(-len(m.activeList()))
+		case key.Matches(msg, keys.ListBottom):
+			m.moveSelection(len(m.activeList()))
+		case key.Matches(msg, keys.Left, keys.Right, keys.NextClue, keys.PrevClue):
+			m.toggleActiveList()
+		case key.Matches(msg, keys.SelectClue):
+			m.focused = false
+			selected := clueSelectedMsg{
+				clue:        m.activeList()[m.selectedIndex()],
+				orientation: m.activeClueOrientation,
+			}
+			return m, func() tea.Msg { return selected }
+		}
+	}
+	return m, nil
+}
+
+// focus gives the clue list keyboard focus, starting the selection on the
+// clue the grid cursor is currently in.
+func (m *cluesModel) focus() {
+	m.focused = true
+	m.activeClueOrientation = m.solvingOrientation
+	m.selectedAcross = indexOfClue(m.acrossClues, m.currentAcrossClue)
+	m.selectedDown = indexOfClue(m.downClues, m.currentDownClue)
+	m.acrossOffset = centeredOffset(m.selectedAcross, len(m.acrossClues), m.numShown)
+	m.downOffset = centeredOffset(m.selectedDown, len(m.downClues), m.numShown)
+}
+
+// trackCursor records the clues under the grid cursor so that they can be
+// highlighted in the list.
+func (m *cluesModel) trackCursor(grid gridModel) {
+	m.currentAcrossClue, m.currentDownClue = grid.currentClues()
+	m.solvingOrientation = grid.navOrientation
+}
+
+func (m cluesModel) activeList() []*puzzle.Clue {
+	if m.activeClueOrientation == Horizontal {
+		return m.acrossClues
+	}
+	return m.downClues
+}
+
+func (m cluesModel) selectedIndex() int {
+	if m.activeClueOrientation == Horizontal {
+		return m.selectedAcross
+	}
+	return m.selectedDown
+}
+
+func (m *cluesModel) toggleActiveList() {
+	if m.activeClueOrientation == Horizontal {
+		m.activeClueOrientation = Vertical
+	} else {
+		m.activeClueOrientation = Horizontal
+	}
+}
+
+func (m *cluesModel) moveSelection(delta int) {
+	selected, offset := &m.selectedAcross, &m.acrossOffset
+	if m.activeClueOrientation == Vertical {
+		selected, offset = &m.selectedDown, &m.downOffset
+	}
+	numClues := len(m.activeList())
+	*selected = min(max(*selected+delta, 0), numClues-1)
+	if *selected < *offset {
+		*offset = *selected
+	} else if *selected >= *offset+m.numShown {
+		*offset = *selected - m.numShown + 1
+	}
 }
 
 func (m cluesModel) View() string {
@@ -46,8 +147,20 @@ func (m cluesModel) View() string {
 		Border(lipgloss.NormalBorder()).
 		Width(CONTAINER_WIDTH).
 		Padding(0, 2)
-	renderedAcrossClues := getClueRendering(currentAcrossClue, acrossClues, Horizontal)
-	renderedDownClues := getClueRendering(currentDownClue, downClues, Vertical)
+	if m.focused {
+		clueContainerStyle = clueContainerStyle.BorderForeground(theme.Primary())
+	}
+
+	var acrossStart, downStart int
+	if m.focused {
+		acrossStart, downStart = m.acrossOffset, m.downOffset
+	} else {
+		acrossStart = centeredOffset(indexOfClue(m.acrossClues, m.currentAcrossClue), len(m.acrossClues), m.numShown)
+		downStart = centeredOffset(indexOfClue(m.downClues, m.currentDownClue), len(m.downClues), m.numShown)
+	}
+
+	renderedAcrossClues := m.getClueRendering(m.currentAcrossClue, m.acrossClues, Horizontal, acrossStart, m.selectedAcross)
+	renderedDownClues := m.getClueRendering(m.currentDownClue, m.downClues, Vertical, downStart, m.selectedDown)
 	acrossHeader := theme.Apply("~~~ ACROSS ~~~")
 	downHeader := theme.Apply("~~~ DOWN ~~~")
 	return clueContainerStyle.Render(lipgloss.JoinHorizontal(lipgloss.Top,
@@ -65,41 +178,41 @@ func (m cluesModel) View() string {
 	))
 }
 
-func getClueRendering(currentClue *puzzle.Clue, clues []*puzzle.Clue, orientation Orientation) string {
-	var currentClueIndex int
-	for i, clue := range clues {
-		if clue == currentClue {
-			currentClueIndex = i
-			break
-		}
-	}
-	rangeStart, rangeEnd := currentClueIndex, currentClueIndex
-	for rangeEnd-rangeStart+1 < NUM_SHOWN_CLUES && rangeStart-rangeEnd+1 != len(clues) {
-		if rangeStart != 0 {
-			rangeStart--
-		}
-
-		if rangeEnd != len(clues)-1 {
-			rangeEnd++
-		}
-	}
+func (m cluesModel) getClueRendering(currentClue *puzzle.Clue, clues []*puzzle.Clue, orientation Orientation, rangeStart int, selectedIndex int) string {
+	rangeEnd := min(rangeStart+m.numShown, len(clues)) - 1
+	showSelection := m.focused && m.activeClueOrientation == orientation
 
 	activeClueStyle := theme.Get().Foreground(theme.Primary())
 	crossClueStyle := theme.Get().Foreground(theme.Secondary())
+	filledClueStyle := theme.Get().Foreground(theme.Muted())
+	referencedClueStyle := theme.Get().Foreground(theme.Tertiary())
+	activeReferences := m.currentAcrossClue.References
+	if m.solvingOrientation == Vertical {
+		activeReferences = m.currentDownClue.References
+	}
 
 	clueList := list.New().
 		Enumerator(func(_ list.Items, i int) string {
 			return fmt.Sprintf("%d. ", clues[i+rangeStart].Num)
 		}).
 		ItemStyleFunc(func(_ list.Items, i int) lipgloss.Style {
-			if currentClue.Num == clues[i+rangeStart].Num {
-				if solvingOrientation == orientation {
-					return activeClueStyle
+			clue := clues[i+rangeStart]
+			style := theme.Get()
+			if currentClue == clue {
+				if m.solvingOrientation == orientation {
+					style = activeClueStyle
 				} else {
-					return crossClueStyle
+					style = crossClueStyle
 				}
+			} else if slices.Contains(activeReferences, clue) {
+				style = referencedClueStyle
+			} else if m.isClueFilled(clue) {
+				style = filledClueStyle
+			}
+			if showSelection && i+rangeStart == selectedIndex {
+				style = style.Reverse(true)
 			}
-			return theme.Get()
+			return style
 		})
 
 	for i := rangeStart; i <= rangeEnd; i++ {
@@ -117,3 +230,29 @@ func getClueRendering(currentClue *puzzle.Clue, clues []*puzzle.Clue, orientatio
 
 	return rendered
 }
+
+func (m cluesModel) isClueFilled(clue *puzzle.Clue) bool {
+	for row := clue.StartRow; row <= clue.EndRow; row++ {
+		for col := clue.StartCol; col <= clue.EndCol; col++ {
+			if (*m.grid)[row][col].content == "-" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func indexOfClue(clues []*puzzle.Clue, target *puzzle.Clue) int {
+	for i, clue := range clues {
+		if clue == target {
+			return i
+		}
+	}
+	return 0
+}
+
+// centeredOffset returns the first index of a window of numShown clues that
+// keeps index as close to the middle as the list bounds allow.
+func centeredOffset(index, numClues, numShown int) int {
+	return max(min(index-numShown/2, numClues-numShown), 0)
+}
@@ -0,0 +1,219 @@
+package solver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/help"
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/stats"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+// completionModel sums up a puzzle once it has been solved.
+type completionModel struct {
+	puzzle    *puzzle.PuzzleDefinition
+	path      string
+	source    string
+	best      *stats.Solve
+	inLibrary bool
+	readOnly  bool
+	help      help.Model
+	shown     bool
+	grid      gridModel
+	elapsed   time.Duration
+	status    string
+	failed    bool
+	// bests are the personal bests by source, shared with the other tabs so
+	// that a solve in one counts in the next. best is the one this solve is
+	// compared against.
+	bests map[string]stats.Solve
+}
+
+func initCompletionModel(puz *puzzle.PuzzleDefinition, path string, options Options) completionModel {
+	help := help.New()
+	help.Styles.ShortKey = theme.Get().Foreground(theme.Primary())
+	help.Styles.ShortDesc = theme.Get().Foreground(theme.Secondary())
+	m := completionModel{
+		puzzle:    puz,
+		path:      path,
+		source:    stats.Source(puz),
+		bests:     options.History.BestBySource,
+		inLibrary: options.InLibrary,
+		readOnly:  options.ReadOnly,
+		help:      help,
+	}
+	return m
+}
+
+// open captures the grid and time the puzzle was solved with, and makes it
+// the best for its source if it beats it.
+func (m *completionModel) open(grid gridModel, elapsed time.Duration) {
+	m.shown = true
+	m.grid = grid
+	m.elapsed = elapsed
+	m.status = ""
+	m.best = nil
+	if best, ok := m.bests[m.source]; ok {
+		m.best = &best
+	}
+	if m.bests != nil && m.clean() && (m.best == nil || elapsed < m.best.Elapsed) {
+		m.bests[m.source] = stats.NewSolve(m.puzzle, elapsed, 0, 0)
+	}
+}
+
+func (m completionModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m completionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		k := m.keys()
+		switch {
+		case key.Matches(msg, k.SaveFill):
+			m.save()
+		case key.Matches(msg, k.ExportShare):
+			return m, m.export()
+		case key.Matches(msg, k.LeaveSolver):
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *completionModel) setStatus(err error, format string, args ...any) {
+	m.failed = err != nil
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.status = fmt.Sprintf(format, args...)
+}
+
+// save writes the solved fill back to the puzzle file.
+func (m *completionModel) save() {
+	if m.path == "" {
+		m.setStatus(fmt.Errorf("no file to save to"), "")
+		return
+	}
+	puz := *m.puzzle
+	puz.CurrentState = m.grid.state()
+	err := loader.SaveFile(m.path, &puz)
+	m.setStatus(err, "saved to %s", m.path)
+}
+
+// export copies the share text to the clipboard and writes it next to the
+// puzzle file, unless the solver is read-only.
+func (m *completionModel) export() tea.Cmd {
+	text := m.shareText()
+	if m.path == "" || m.readOnly {
+		m.setStatus(nil, "copied to clipboard")
+		return tea.SetClipboard(text)
+	}
+	path := strings.TrimSuffix(m.path, filepath.Ext(m.path)) + ".share.txt"
+	err := os.WriteFile(path, []byte(text), 0644)
+	m.setStatus(err, "copied to clipboard and saved to %s", path)
+	return tea.SetClipboard(text)
+}
+
+func (m completionModel) title() string {
+	if m.puzzle.Title == "" {
+		return filepath.Base(m.path)
+	}
+	return m.puzzle.Title
+}
+
+func (m completionModel) clean() bool {
+	return m.grid.checks == 0 && m.grid.reveals == 0
+}
+
+func (m completionModel) shareText() string {
+	help := "clean"
+	if !m.clean() {
+		help = fmt.Sprintf("%s, %s", plural(m.grid.checks, "check"), plural(m.grid.reveals, "reveal"))
+	}
+	return fmt.Sprintf("cruciterm · %s\n%s · %s · %s\n%s",
+		m.title(), m.source, stats.FormatDuration(m.elapsed), help, m.grid.shareGrid())
+}
+
+// comparison describes the solve against the personal best for its source.
+func (m completionModel) comparison() string {
+	switch {
+	case !m.clean() && m.best == nil:
+		return fmt.Sprintf("no clean %s solves yet", m.source)
+	case !m.clean():
+		return fmt.Sprintf("%s best is %s; only clean solves count", m.source, stats.FormatDuration(m.best.Elapsed))
+	case m.best == nil:
+		return fmt.Sprintf("first clean %s solve, a new personal best!", m.source)
+	case m.elapsed < m.best.Elapsed:
+		return fmt.Sprintf("new %s personal best, %s faster than %s!",
+			m.source, stats.FormatDuration(m.best.Elapsed-m.elapsed), stats.FormatDuration(m.best.Elapsed))
+	}
+	return fmt.Sprintf("%s behind your %s best of %s",
+		stats.FormatDuration(m.elapsed-m.best.Elapsed), m.source, stats.FormatDuration(m.best.Elapsed))
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func (m completionModel) View() string {
+	containerStyle := theme.Get().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Green()).
+		Padding(0, 2)
+	titleStyle := theme.Get().Foreground(theme.Green()).Bold(true)
+	labelStyle := theme.Get().Foreground(theme.Secondary())
+	highlightStyle := theme.Get().Foreground(theme.Primary())
+
+	row := func(label, value string) string {
+		return labelStyle.Render(fmt.Sprintf("%-16s", label)) + theme.Apply(value)
+	}
+	rows := []string{
+		titleStyle.Render("Solved! ") + theme.Apply(m.title()),
+		"",
+		row("time", stats.FormatDuration(m.elapsed)),
+		row("checks", fmt.Sprint(m.grid.checks)),
+		row("reveals", fmt.Sprint(m.grid.reveals)),
+		row("corrections", fmt.Sprint(m.grid.corrections)),
+		row("letters typed", fmt.Sprintf("%d for %d squares", m.grid.lettersTyped, m.grid.numCells())),
+		"",
+		highlightStyle.Render(m.comparison()),
+		"",
+		theme.Apply(strings.TrimSuffix(m.grid.shareGrid(), "\n")),
+	}
+	if m.status != "" {
+		statusStyle := theme.Get().Foreground(theme.Muted())
+		if m.failed {
+			statusStyle = theme.Get().Foreground(theme.Red())
+		}
+		rows = append(rows, "", statusStyle.Render(m.status))
+	}
+	rows = append(rows, "", m.help.View(completionKeyMap(m.keys())))
+	return containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// keys labels LeaveSolver by where it leads, and leaves out saving the fill
+// when the solver is read-only.
+func (m completionModel) keys() keyMap {
+	k := keys
+	if m.inLibrary {
+		k.LeaveSolver.SetHelp(k.LeaveSolver.Help().Key, "back to library")
+	}
+	if m.readOnly {
+		k.SaveFill.SetEnabled(false)
+		k.ExportShare.SetHelp(k.ExportShare.Help().Key, "copy share text")
+	}
+	return k
+}
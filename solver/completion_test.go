@@ -0,0 +1,114 @@
+package solver
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/tylerwgrass/cruciterm/stats"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+// newTestCompletion is the completion screen of a puzzle loaded from dir,
+// with the grid to open it with.
+func newTestCompletion(dir string, options Options) (completionModel, gridModel) {
+	theme.Init()
+	puz := newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "clue")
+	return initCompletionModel(puz, filepath.Join(dir, "puzzle.json"), options), initGridModel(puz, nil)
+}
+
+func TestCompletionReadOnly(t *testing.T) {
+	files := func(dir string) []string {
+		t.Helper()
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	for _, readOnly := range []bool{false, true} {
+		dir := t.TempDir()
+		completion, grid := newTestCompletion(dir, Options{ReadOnly: readOnly})
+		completion.open(grid, time.Minute)
+		m := typeKeys(completion, "s")
+		m, cmd := m.Update(tea.KeyPressMsg{Code: 'x', Text: "x"})
+		if cmd == nil {
+			t.Errorf("read-only %v: exporting didn't copy the share text", readOnly)
+		}
+		want := []string{"puzzle.json", "puzzle.share.txt"}
+		if readOnly {
+			want = nil
+		}
+		if got := files(dir); !slices.Equal(got, want) {
+			t.Errorf("read-only %v: wrote %q, want %q", readOnly, got, want)
+		}
+		if help := m.(completionModel).View(); readOnly == strings.Contains(help, "save to file") {
+			t.Errorf("read-only %v: help is %q", readOnly, help)
+		}
+	}
+}
+
+func TestCompletionComparison(t *testing.T) {
+	source := stats.Source(newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "clue"))
+	best := stats.Solve{Source: source, Elapsed: 2 * time.Minute, Clean: true}
+	tests := []struct {
+		name    string
+		best    *stats.Solve
+		elapsed time.Duration
+		checks  int
+		want    string
+	}{
+		{"first", nil, time.Minute, 0, "first clean " + source + " solve, a new personal best!"},
+		{"faster", &best, time.Minute, 0, "new " + source + " personal best, 1:00 faster than 2:00!"},
+		{"slower", &best, 3 * time.Minute, 0, "1:00 behind your " + source + " best of 2:00"},
+		{"with help", &best, time.Minute, 1, source + " best is 2:00; only clean solves count"},
+		{"with help and no best", nil, time.Minute, 1, "no clean " + source + " solves yet"},
+	}
+	for _, test := range tests {
+		bests := make(map[string]stats.Solve)
+		if test.best != nil {
+			bests[source] = *test.best
+		}
+		m, grid := newTestCompletion(t.TempDir(), Options{History: stats.Summary{BestBySource: bests}})
+		grid.checks = test.checks
+		m.open(grid, test.elapsed)
+		if got := m.comparison(); got != test.want {
+			t.Errorf("%s: %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCompletionBestIsShared(t *testing.T) {
+	theme.Init()
+	sessions := []Session{
+		{Puzzle: newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "clue")},
+		{Puzzle: newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "clue")},
+		{Puzzle: newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "clue")},
+	}
+	tabs := initTabsModel(sessions, Options{})
+	first, second, third := tabs.tabs[0].completion, tabs.tabs[1].completion, tabs.tabs[2].completion
+	first.open(first.grid, 2*time.Minute)
+	second.open(second.grid, 3*time.Minute)
+	third.open(third.grid, time.Minute)
+	if first.best != nil {
+		t.Errorf("the first solve was compared against %v", first.best)
+	}
+	if second.best == nil || second.best.Elapsed != 2*time.Minute {
+		t.Errorf("the second solve was compared against %v, want the first", second.best)
+	}
+	if third.best == nil || third.best.Elapsed != 2*time.Minute {
+		t.Errorf("the third solve was compared against %v, want the first", third.best)
+	}
+	if best := first.bests[first.source]; best.Elapsed != time.Minute {
+		t.Errorf("best is now %v, want the third solve", best.Elapsed)
+	}
+}
@@ -0,0 +1,272 @@
+package solver
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/tylerwgrass/cruciterm/coop"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+type coopMsg coop.Message
+
+type coopClosedMsg struct{}
+
+// peerCursor is where another player's cursor is on the grid.
+type peerCursor struct {
+	id   int
+	name string
+	row  int
+	col  int
+	down bool
+}
+
+func peerColor(id int) color.Color {
+	colors := []color.Color{theme.Tertiary(), theme.Green(), theme.Secondary(), theme.Red()}
+	return colors[id%len(colors)]
+}
+
+// coopConn is the connection to a co-op or race host that a session
+// receives from and sends through.
+type coopConn struct {
+	client       *coop.Client
+	disconnected bool
+}
+
+// wait delivers the next message from the server.
+func (c *coopConn) wait() tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-c.client.Messages()
+		if !ok {
+			return coopClosedMsg{}
+		}
+		return coopMsg(msg)
+	}
+}
+
+func (c *coopConn) send(msg coop.Message) {
+	if c.disconnected {
+		return
+	}
+	if err := c.client.Send(msg); err != nil {
+		c.disconnected = true
+	}
+}
+
+// lostStatus says the host has gone.
+func (c *coopConn) lostStatus() string {
+	return theme.Get().Foreground(theme.Red()).Render("disconnected from " + c.client.Address)
+}
+
+// coopSession keeps a grid in step with the other players. Local changes
+// are found by comparing the grid with how it was last sent, like recorder.
+//
+// Spectators send nothing, and their cursor follows one of the players.
+type coopSession struct {
+	coopConn
+	state      string
+	row        int
+	col        int
+	down       bool
+	checks     int
+	peers      map[int]*peerCursor
+	spectating bool
+	spectators int
+	// following is the player a spectator's cursor follows, or 0 for none.
+	following int
+}
+
+func newCoopSession(client *coop.Client, grid gridModel) *coopSession {
+	s := &coopSession{
+		coopConn:   coopConn{client: client},
+		state:      grid.state(),
+		row:        -1,
+		col:        -1,
+		checks:     grid.checks,
+		peers:      make(map[int]*peerCursor),
+		spectating: client.Spectator,
+		spectators: client.Spectators,
+	}
+	for _, player := range client.Players {
+		s.peers[player.ID] = &peerCursor{id: player.ID, name: player.Name, row: player.Row, col: player.Col, down: player.Down}
+	}
+	if s.spectating {
+		s.followNext()
+	}
+	return s
+}
+
+// observe sends the local changes made to grid since the last call.
+func (s *coopSession) observe(grid gridModel) {
+	if s.spectating {
+		return
+	}
+	cells := *grid.navigator.grid
+	numCols := len(cells[0])
+	state := grid.state()
+	for i := range state {
+		if state[i] != s.state[i] {
+			cell := cells[i/numCols][i%numCols]
+			s.send(coop.Message{Type: coop.Edit, Row: i / numCols, Col: i % numCols, Fill: cell.content, Revealed: cell.revealed})
+		}
+	}
+	s.state = state
+
+	if grid.checks != s.checks {
+		s.checks = grid.checks
+		var wrong []coop.Square
+		for i, row := range cells {
+			for j, cell := range row {
+				if cell.wrong {
+					wrong = append(wrong, coop.Square{Row: i, Col: j})
+				}
+			}
+		}
+		if len(wrong) > 0 {
+			s.send(coop.Message{Type: coop.Marks, Squares: wrong})
+		}
+	}
+
+	down := grid.navOrientation == Vertical
+	if grid.cursorY != s.row || grid.cursorX != s.col || down != s.down {
+		s.row, s.col, s.down = grid.cursorY, grid.cursorX, down
+		s.send(coop.Message{Type: coop.Cursor, Row: s.row, Col: s.col, Down: down})
+	}
+}
+
+// apply makes a change from the server to grid.
+func (s *coopSession) apply(grid *gridModel, msg coopMsg) {
+	cells := *grid.navigator.grid
+	inGrid := func(row, col int) bool {
+		return row >= 0 && row < len(cells) && col >= 0 && col < len(cells[row]) && cells[row][col].content != "."
+	}
+	switch coop.MessageType(msg.Type) {
+	case coop.Edit:
+		if !inGrid(msg.Row, msg.Col) {
+			return
+		}
+		cell := &cells[msg.Row][msg.Col]
+		if cell.content != msg.Fill {
+			cell.content, cell.wrong = msg.Fill, false
+		}
+		cell.revealed = msg.Revealed
+		s.state = grid.state()
+		grid.validateSolution()
+	case coop.Marks:
+		for _, square := range msg.Squares {
+			if inGrid(square.Row, square.Col) {
+				cells[square.Row][square.Col].wrong = true
+			}
+		}
+	case coop.Join:
+		s.peers[msg.Player] = &peerCursor{id: msg.Player, name: msg.Name, row: -1, col: -1}
+		if s.spectating && s.following == 0 {
+			s.following = msg.Player
+		}
+	case coop.Leave:
+		delete(s.peers, msg.Player)
+		if msg.Player == s.following {
+			s.followNext()
+		}
+	case coop.Cursor:
+		if peer, ok := s.peers[msg.Player]; ok {
+			peer.row, peer.col, peer.down = msg.Row, msg.Col, msg.Down
+		}
+	case coop.Audience:
+		s.spectators = msg.Spectators
+	}
+	s.follow(grid)
+	grid.peers = s.cursors()
+}
+
+// followNext moves a spectator on to the player after the one they follow.
+func (s *coopSession) followNext() {
+	var ids []int
+	for id := range s.peers {
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		s.following = 0
+		return
+	}
+	slices.Sort(ids)
+	i, _ := slices.BinarySearch(ids, s.following+1)
+	s.following = ids[i%len(ids)]
+}
+
+// follow puts a spectator's cursor where the player they follow has theirs.
+func (s *coopSession) follow(grid *gridModel) {
+	peer, ok := s.peers[s.following]
+	if !s.spectating || !ok || peer.row < 0 {
+		return
+	}
+	grid.cursorY, grid.cursorX = peer.row, peer.col
+	grid.navOrientation = Horizontal
+	if peer.down {
+		grid.navOrientation = Vertical
+	}
+}
+
+// cursors are the other players' cursors, leaving out the one a spectator's
+// cursor already shows.
+func (s *coopSession) cursors() []peerCursor {
+	cursors := make([]peerCursor, 0, len(s.peers))
+	for _, peer := range s.peers {
+		if peer.id != s.following {
+			cursors = append(cursors, *peer)
+		}
+	}
+	slices.SortFunc(cursors, func(a, b peerCursor) int { return a.id - b.id })
+	return cursors
+}
+
+// status lists who else is solving and how many are watching, or says the
+// host has gone.
+func (s *coopSession) status() string {
+	if s.disconnected {
+		return s.lostStatus()
+	}
+	var status string
+	cursors := s.cursors()
+	switch {
+	case s.spectating && s.following == 0:
+		status = theme.Get().Foreground(theme.Muted()).Render("watching " + s.client.Address + ", waiting for players")
+	case s.spectating:
+		status = theme.Apply("watching ") + peerName(*s.peers[s.following], theme.Foreground()) + theme.Apply(" at "+s.client.Address)
+		if len(cursors) > 0 {
+			status += theme.Apply(" with ") + peerNames(cursors)
+		}
+	case len(cursors) == 0:
+		status = theme.Get().Foreground(theme.Muted()).Render("co-op at " + s.client.Address + ", waiting for players")
+	default:
+		status = theme.Apply("co-op at "+s.client.Address+" with ") + peerNames(cursors)
+	}
+
+	watching := s.spectators
+	if s.spectating {
+		watching--
+	}
+	if watching > 0 {
+		status += theme.Get().Foreground(theme.Muted()).Render(fmt.Sprintf(", %d watching", watching))
+	}
+	return status
+}
+
+func peerName(peer peerCursor, c color.Color) string {
+	name := peer.name
+	if name == "" {
+		name = fmt.Sprintf("player %d", peer.id)
+	}
+	return theme.Get().Foreground(c).Render(name)
+}
+
+func peerNames(cursors []peerCursor) string {
+	names := make([]string, len(cursors))
+	for i, peer := range cursors {
+		names[i] = peerName(peer, peerColor(peer.id))
+	}
+	return strings.Join(names, theme.Apply(", "))
+}
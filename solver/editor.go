@@ -0,0 +1,704 @@
+package solver
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/help"
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/theme"
+	"github.com/tylerwgrass/cruciterm/wordlist"
+)
+
+// MAX_GRID_SIZE is the most rows or columns a grid can be resized to.
+var MAX_GRID_SIZE int = 30
+
+// MIN_WORD_LENGTH is the shortest entry the editor doesn't warn about.
+var MIN_WORD_LENGTH int = 3
+
+var gridSizePattern = regexp.MustCompile(`^(\d+)\s*[x×*]\s*(\d+)$`)
+
+// EditorOptions configure the editor.
+type EditorOptions struct {
+	// Path is where the puzzle is saved. Its extension picks the format.
+	Path string
+	// Symmetric starts out keeping the blocks in rotational symmetry.
+	Symmetric bool
+	// Unsaved marks the puzzle as different from the file, e.g. when it is
+	// new.
+	Unsaved bool
+	// WordList is what autofill fills the grid from. Autofill is unavailable
+	// without one.
+	WordList *wordlist.List
+	// Fill tunes autofill.
+	Fill FillOptions
+	// Terminal runs the editor somewhere other than the process's own
+	// terminal when set.
+	Terminal *Terminal
+}
+
+type editorView int
+
+const (
+	editingGrid editorView = iota
+	editingClue
+	resizingGrid
+)
+
+// clueKey identifies an entry by where it starts, so that its clue can follow
+// it when the grid is renumbered.
+type clueKey struct {
+	direction puzzle.Direction
+	row       int
+	col       int
+}
+
+func keyOf(clue *puzzle.Clue) clueKey {
+	return clueKey{clue.Direction, clue.StartRow, clue.StartCol}
+}
+
+// editorModel builds a puzzle: its blocks, its answers and its clues. Empty
+// squares are kept as "-" in the answer, as they are in a solver's grid.
+type editorModel struct {
+	puzzle      puzzle.PuzzleDefinition
+	path        string
+	cursorX     int
+	cursorY     int
+	orientation Orientation
+	// symmetric mirrors every block toggled to the opposite square.
+	symmetric bool
+	view      editorView
+	input     textinput.Model
+	help      help.Model
+	status    string
+	failed    bool
+	// dirty is set while there are unsaved changes, and confirmQuit once
+	// quitting has been asked for with unsaved changes.
+	dirty       bool
+	confirmQuit bool
+	words       *wordlist.List
+	fillOptions FillOptions
+	// cancelFill stops the autofill running in the background, if any.
+	cancelFill context.CancelFunc
+	width      int
+	height     int
+}
+
+// fillDoneMsg carries the answer found by an autofill.
+type fillDoneMsg struct {
+	answer  string
+	err     error
+	elapsed time.Duration
+}
+
+func initEditorModel(puz puzzle.PuzzleDefinition, options EditorOptions) editorModel {
+	puz.Circles = slices.Clone(puz.Circles)
+	puz.Rebus = maps.Clone(puz.Rebus)
+	input := textinput.New()
+	input.Styles.Focused.Prompt = theme.Get().Foreground(theme.Primary())
+	input.Styles.Focused.Text = theme.Get()
+	input.Styles.Focused.Placeholder = theme.Get().Foreground(theme.Muted())
+	help := help.New()
+	help.Styles.FullKey = theme.Get().Foreground(theme.Primary())
+	help.Styles.FullDesc = theme.Get().Foreground(theme.Secondary())
+	help.ShowAll = true
+	m := editorModel{
+		puzzle:      puz,
+		path:        options.Path,
+		symmetric:   options.Symmetric,
+		fillOptions: options.Fill,
+		dirty:       options.Unsaved,
+		words:       options.WordList,
+		input:       input,
+		help:        help,
+	}
+	m.renumber()
+	return m
+}
+
+func (m editorModel) Init() tea.Cmd {
+	return tea.RequestBackgroundColor
+}
+
+func (m editorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.BackgroundColorMsg:
+		return m, tea.SetBackgroundColor(theme.Background())
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+	case tea.KeyMsg:
+		if m.cancelFill != nil {
+			return m.updateFilling(msg)
+		}
+		if m.view != editingGrid {
+			return m.updatePrompt(msg)
+		}
+		return m.updateGrid(msg)
+	case fillDoneMsg:
+		m.finishFill(msg)
+		return m, nil
+	}
+	if m.view != editingGrid {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m editorModel) updateGrid(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !key.Matches(msg, keys.Quit) {
+		m.confirmQuit = false
+	}
+	if ok, _ := regexp.MatchString(`^[a-zA-Z0-9]$`, msg.String()); ok {
+		if m.at(m.cursorY, m.cursorX) != '.' {
+			m.set(m.cursorY, m.cursorX, strings.ToUpper(msg.String())[0])
+			m.step(Forward)
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, keys.Quit):
+		if m.dirty && !m.confirmQuit {
+			m.confirmQuit = true
+			m.setStatus(fmt.Errorf("there are unsaved changes; press %s again to quit without saving", keys.Quit.Help().Key), "")
+			return m, nil
+		}
+		return m, tea.Quit
+	case key.Matches(msg, keys.SavePuzzle):
+		m.save()
+	case key.Matches(msg, keys.ToggleBlock):
+		m.toggleBlock()
+	case key.Matches(msg, keys.ToggleSymmetry):
+		m.symmetric = !m.symmetric
+		switch {
+		case !m.symmetric:
+			m.setStatus(nil, "blocks are no longer mirrored")
+		case m.isSymmetric():
+			m.setStatus(nil, "blocks are mirrored to keep the grid symmetric")
+		default:
+			m.setStatus(nil, "blocks are mirrored from now on, but the grid isn't symmetric yet")
+		}
+	case key.Matches(msg, keys.EditClue):
+		return m, m.openPrompt(editingClue)
+	case key.Matches(msg, keys.ResizeGrid):
+		return m, m.openPrompt(resizingGrid)
+	case key.Matches(msg, keys.Autofill):
+		return m, m.startFill()
+	case key.Matches(msg, keys.ToggleDirection):
+		m.orientation = m.orientation.cross()
+	case key.Matches(msg, keys.Delete):
+		if m.at(m.cursorY, m.cursorX) != '.' {
+			m.set(m.cursorY, m.cursorX, '-')
+			m.step(Reverse)
+		}
+	case key.Matches(msg, keys.NextClue):
+		m.jumpToEntry(1)
+	case key.Matches(msg, keys.PrevClue):
+		m.jumpToEntry(-1)
+	case key.Matches(msg, keys.Up):
+		m.cursorY = max(m.cursorY-1, 0)
+	case key.Matches(msg, keys.Down):
+		m.cursorY = min(m.cursorY+1, m.puzzle.NumRows-1)
+	case key.Matches(msg, keys.Left):
+		m.cursorX = max(m.cursorX-1, 0)
+	case key.Matches(msg, keys.Right):
+		m.cursorX = min(m.cursorX+1, m.puzzle.NumCols-1)
+	}
+	return m, nil
+}
+
+// updateFilling only lets the autofill be stopped, as the grid mustn't change
+// under it.
+func (m editorModel) updateFilling(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		m.cancelFill()
+		return m.updateGrid(msg)
+	case key.Matches(msg, keys.Back):
+		m.cancelFill()
+	}
+	return m, nil
+}
+
+// startFill fills the grid from the word list in the background.
+func (m *editorModel) startFill() tea.Cmd {
+	if m.words == nil {
+		m.setStatus(fmt.Errorf("no word list to fill from; give one with -words or \"wordList\" in the config file"), "")
+		return nil
+	}
+	if !strings.Contains(m.puzzle.Answer, "-") {
+		m.setStatus(nil, "the grid is already full")
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelFill = cancel
+	m.setStatus(nil, "filling from %s… (%s to stop)", plural(m.words.Len(), "word"), keys.Back.Help().Key)
+	puz, words, options := m.puzzle, m.words, m.fillOptions
+	return func() tea.Msg {
+		start := time.Now()
+		answer, err := FillPuzzle(ctx, &puz, words, options)
+		return fillDoneMsg{answer: answer, err: err, elapsed: time.Since(start)}
+	}
+}
+
+func (m *editorModel) finishFill(msg fillDoneMsg) {
+	m.cancelFill()
+	m.cancelFill = nil
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		// Keep asking to confirm when it was stopped by quitting.
+		if !m.confirmQuit {
+			m.setStatus(nil, "stopped filling")
+		}
+		return
+	case msg.err != nil:
+		m.setStatus(msg.err, "")
+		return
+	}
+	filled := strings.Count(m.puzzle.Answer, "-") - strings.Count(msg.answer, "-")
+	m.puzzle.Answer = msg.answer
+	m.dirty = true
+	m.confirmQuit = false
+	m.renumber()
+	m.setStatus(nil, "filled %s in %s", plural(filled, "square"), msg.elapsed.Round(time.Millisecond))
+}
+
+func (m editorModel) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		m.closePrompt()
+		return m.updateGrid(msg)
+	case key.Matches(msg, keys.Back):
+		m.closePrompt()
+		return m, nil
+	case key.Matches(msg, keys.SelectClue):
+		m.submitPrompt()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *editorModel) openPrompt(view editorView) tea.Cmd {
+	m.input.Reset()
+	switch view {
+	case editingClue:
+		clue := m.activeClue()
+		if clue == nil {
+			return nil
+		}
+		m.input.Prompt = fmt.Sprintf("%d %s: ", clue.Num, orientationName(m.orientation))
+		m.input.Placeholder = "clue"
+		m.input.SetValue(clue.Clue)
+	case resizingGrid:
+		m.input.Prompt = "grid size: "
+		m.input.Placeholder = fmt.Sprintf("rows x columns, now %dx%d", m.puzzle.NumRows, m.puzzle.NumCols)
+	}
+	m.view = view
+	m.status = ""
+	return m.input.Focus()
+}
+
+func (m *editorModel) closePrompt() {
+	m.view = editingGrid
+	m.input.Blur()
+}
+
+func (m *editorModel) submitPrompt() {
+	value := strings.TrimSpace(m.input.Value())
+	switch m.view {
+	case editingClue:
+		if clue := m.activeClue(); clue != nil && clue.Clue != value {
+			clue.Clue = value
+			m.puzzle.LinkReferences()
+			m.dirty = true
+		}
+	case resizingGrid:
+		matches := gridSizePattern.FindStringSubmatch(value)
+		if matches == nil {
+			m.setStatus(fmt.Errorf("enter a size such as 15x15"), "")
+			return
+		}
+		rows, _ := strconv.Atoi(matches[1])
+		cols, _ := strconv.Atoi(matches[2])
+		if rows < 2 || cols < 2 || rows > MAX_GRID_SIZE || cols > MAX_GRID_SIZE {
+			m.setStatus(fmt.Errorf("grids can be from 2x2 to %dx%d", MAX_GRID_SIZE, MAX_GRID_SIZE), "")
+			return
+		}
+		m.resize(rows, cols)
+		m.setStatus(nil, "resized to %dx%d", rows, cols)
+	}
+	m.closePrompt()
+}
+
+func (m *editorModel) setStatus(err error, format string, args ...any) {
+	m.failed = err != nil
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.status = fmt.Sprintf(format, args...)
+}
+
+func (m editorModel) at(row, col int) byte {
+	return m.puzzle.Answer[row*m.puzzle.NumCols+col]
+}
+
+// set changes one square of the answer to a letter, a block or "-" for
+// empty, and renumbers the grid.
+func (m *editorModel) set(row, col int, content byte) {
+	i := row*m.puzzle.NumCols + col
+	if m.puzzle.Answer[i] == content {
+		return
+	}
+	answer := []byte(m.puzzle.Answer)
+	answer[i] = content
+	m.puzzle.Answer = string(answer)
+	delete(m.puzzle.Rebus, i)
+	if content == '.' && m.puzzle.Circles != nil {
+		m.puzzle.Circles[i] = false
+	}
+	m.dirty = true
+	m.renumber()
+}
+
+// renumber assigns clue numbers to the grid the way loaded puzzles are
+// numbered, keeping the clues of entries that still start where they did.
+func (m *editorModel) renumber() {
+	puz := &m.puzzle
+	clues := make(map[clueKey]string)
+	for _, clue := range slices.Concat(puz.AcrossClues, puz.DownClues) {
+		clues[keyOf(clue)] = clue.Clue
+	}
+	puz.AssignClues(make([]string, len(puz.Answer)*2))
+	for _, clue := range slices.Concat(puz.AcrossClues, puz.DownClues) {
+		clue.Clue = clues[keyOf(clue)]
+	}
+	puz.NumClues = len(puz.AcrossClues) + len(puz.DownClues)
+	puz.LinkReferences()
+	puz.CurrentState = strings.Map(func(r rune) rune {
+		if r == '.' {
+			return '.'
+		}
+		return '-'
+	}, puz.Answer)
+}
+
+func (m *editorModel) toggleBlock() {
+	content := byte('.')
+	if m.at(m.cursorY, m.cursorX) == '.' {
+		content = '-'
+	}
+	m.set(m.cursorY, m.cursorX, content)
+	if m.symmetric {
+		m.set(m.puzzle.NumRows-1-m.cursorY, m.puzzle.NumCols-1-m.cursorX, content)
+	}
+}
+
+// isSymmetric reports whether every block has a block opposite it.
+func (m editorModel) isSymmetric() bool {
+	answer := m.puzzle.Answer
+	for i := range answer {
+		if (answer[i] == '.') != (answer[len(answer)-1-i] == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// resize changes the size of the grid, keeping whatever still fits from the
+// top left.
+func (m *editorModel) resize(rows, cols int) {
+	old := m.puzzle
+	answer := make([]byte, rows*cols)
+	var circles []bool
+	if old.Circles != nil {
+		circles = make([]bool, rows*cols)
+	}
+	rebus := make(map[int]string)
+	for i := range answer {
+		row, col := i/cols, i%cols
+		if row >= old.NumRows || col >= old.NumCols {
+			answer[i] = '-'
+			continue
+		}
+		j := row*old.NumCols + col
+		answer[i] = old.Answer[j]
+		if circles != nil {
+			circles[i] = old.Circles[j]
+		}
+		if full, ok := old.Rebus[j]; ok {
+			rebus[i] = full
+		}
+	}
+	m.puzzle.NumRows, m.puzzle.NumCols = rows, cols
+	m.puzzle.Answer = string(answer)
+	m.puzzle.Circles = circles
+	m.puzzle.Rebus = rebus
+	m.cursorY, m.cursorX = min(m.cursorY, rows-1), min(m.cursorX, cols-1)
+	m.dirty = true
+	m.renumber()
+}
+
+// step moves the cursor one square along the orientation, unless that would
+// leave the entry.
+func (m *editorModel) step(direction Direction) {
+	row, col := m.cursorY, m.cursorX
+	if m.orientation == Vertical {
+		row += int(direction)
+	} else {
+		col += int(direction)
+	}
+	if row < 0 || col < 0 || row >= m.puzzle.NumRows || col >= m.puzzle.NumCols || m.at(row, col) == '.' {
+		return
+	}
+	m.cursorY, m.cursorX = row, col
+}
+
+func (m editorModel) entries() []*puzzle.Clue {
+	if m.orientation == Vertical {
+		return m.puzzle.DownClues
+	}
+	return m.puzzle.AcrossClues
+}
+
+// activeClue is the entry under the cursor in the typing orientation, or nil
+// on a block.
+func (m editorModel) activeClue() *puzzle.Clue {
+	return m.entryAt(m.entries(), m.cursorY, m.cursorX)
+}
+
+func (m editorModel) entryAt(entries []*puzzle.Clue, row, col int) *puzzle.Clue {
+	for _, clue := range entries {
+		if isCellInAnyClue([]*puzzle.Clue{clue}, row, col) {
+			return clue
+		}
+	}
+	return nil
+}
+
+// jumpToEntry moves the cursor to the start of the entry offset entries from
+// the active one, wrapping around.
+func (m *editorModel) jumpToEntry(offset int) {
+	entries := m.entries()
+	if len(entries) == 0 {
+		return
+	}
+	i := slices.Index(entries, m.activeClue())
+	if i == -1 && offset < 0 {
+		i = 0
+	}
+	next := entries[((i+offset)%len(entries)+len(entries))%len(entries)]
+	m.cursorY, m.cursorX = next.StartRow, next.StartCol
+}
+
+func (m *editorModel) save() {
+	// The solver needs somewhere to put the cursor in either direction.
+	if len(m.puzzle.AcrossClues) == 0 || len(m.puzzle.DownClues) == 0 {
+		m.setStatus(errors.New("the grid needs an entry across and one down before it can be saved"), "")
+		return
+	}
+	err := loader.SaveFile(m.path, &m.puzzle)
+	if err != nil {
+		m.setStatus(err, "")
+		return
+	}
+	m.dirty = false
+	m.confirmQuit = false
+	if empty := strings.Count(m.puzzle.Answer, "-"); empty > 0 {
+		m.setStatus(nil, "saved to %s with %s still empty", m.path, plural(empty, "square"))
+		return
+	}
+	m.setStatus(nil, "saved to %s", m.path)
+}
+
+// pattern is the fill of an entry, with "_" for empty squares.
+func (m editorModel) pattern(clue *puzzle.Clue) string {
+	var sb strings.Builder
+	for row := clue.StartRow; row <= clue.EndRow; row++ {
+		for col := clue.StartCol; col <= clue.EndCol; col++ {
+			if content := m.at(row, col); content == '-' {
+				sb.WriteByte('_')
+			} else {
+				sb.WriteByte(content)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func (m editorModel) View() string {
+	title := cmp.Or(m.puzzle.Title, "Untitled")
+	header := theme.Get().PaddingTop(m.height / 20).Render(fmt.Sprintf("%s\n%s", title,
+		theme.Get().Foreground(theme.Muted()).Render(m.path)))
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, m.gridView(), "    ", m.cluesView())
+	var footer string
+	if m.view != editingGrid {
+		footer = m.input.View()
+	} else {
+		footer = m.help.View(editorKeyMap(keys))
+	}
+	statusStyle := theme.Get().Foreground(theme.Muted())
+	if m.failed {
+		statusStyle = theme.Get().Foreground(theme.Red())
+	}
+	content := lipgloss.JoinVertical(lipgloss.Center,
+		header,
+		m.summary(),
+		m.clueBar(lipgloss.Width(body)),
+		body,
+		"",
+		statusStyle.Render(m.status),
+		footer,
+	)
+	return theme.Get().Width(m.width).Height(m.height).Render(
+		lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Top, content))
+}
+
+// summary describes the grid and what is left to do.
+func (m editorModel) summary() string {
+	puz := m.puzzle
+	parts := []string{
+		fmt.Sprintf("%dx%d", puz.NumRows, puz.NumCols),
+		plural(puz.NumClues, "word"),
+		"typing " + orientationName(m.orientation),
+	}
+	if m.symmetric {
+		parts = append(parts, "symmetric")
+	}
+	var warnings []string
+	entries := slices.Concat(puz.AcrossClues, puz.DownClues)
+	if n := len(slices.DeleteFunc(slices.Clone(entries), func(c *puzzle.Clue) bool { return len(c.Answer) >= MIN_WORD_LENGTH })); n > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s shorter than %d letters", plural(n, "word"), MIN_WORD_LENGTH))
+	}
+	if n := len(slices.DeleteFunc(slices.Clone(entries), func(c *puzzle.Clue) bool { return c.Clue != "" })); n > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s without a clue", plural(n, "word")))
+	}
+	summary := theme.Get().Foreground(theme.Secondary()).Render(strings.Join(parts, " · "))
+	if len(warnings) > 0 {
+		summary += "\n" + theme.Get().Foreground(theme.Red()).Render(strings.Join(warnings, " · "))
+	}
+	return summary
+}
+
+func (m editorModel) clueBar(width int) string {
+	barStyle := theme.Get().
+		Border(lipgloss.NormalBorder(), false, false, true, false).
+		BorderForeground(theme.Primary()).
+		Width(width).
+		Padding(0, 1)
+	clue := m.activeClue()
+	if clue == nil {
+		return barStyle.Render(theme.Get().Foreground(theme.Muted()).Render(
+			fmt.Sprintf("%s to make this a white square", keys.ToggleBlock.Help().Key)))
+	}
+	label := fmt.Sprintf("%d%s", clue.Num, string(orientationName(m.orientation)[0]))
+	text := theme.Get().Render(clue.Clue)
+	if clue.Clue == "" {
+		text = theme.Get().Foreground(theme.Muted()).Render(
+			fmt.Sprintf("no clue yet; %s to write one", keys.EditClue.Help().Key))
+	}
+	labelStyle := theme.Get().Foreground(theme.Primary()).Bold(true)
+	return barStyle.Render(lipgloss.JoinHorizontal(lipgloss.Top,
+		labelStyle.Width(6).Render(label),
+		labelStyle.Render(m.pattern(clue)),
+		theme.Get().Foreground(theme.Muted()).Render(fmt.Sprintf(" (%d)  ", clue.EndRow-clue.StartRow+clue.EndCol-clue.StartCol+1)),
+		text,
+	))
+}
+
+func (m editorModel) gridView() string {
+	activeStyle := theme.Get().Foreground(theme.Primary())
+	cursorStyle := theme.Get().Background(theme.Primary()).Foreground(theme.Background())
+	active := m.activeClue()
+	sb := theme.NewThemedStringBuilder(theme.Get())
+	for row := range m.puzzle.NumRows {
+		sb.WriteString(" ")
+		for col := range m.puzzle.NumCols {
+			content := string(m.at(row, col))
+			switch content {
+			case ".":
+				content = "■"
+			case "-":
+				content = "_"
+			}
+			if full, ok := m.puzzle.Rebus[row*m.puzzle.NumCols+col]; ok && len(full) > 1 {
+				content = strings.ToLower(content)
+			}
+			switch {
+			case row == m.cursorY && col == m.cursorX:
+				sb.WriteStyledString(content, cursorStyle)
+				sb.WriteString(" ")
+			case active != nil && isCellInAnyClue([]*puzzle.Clue{active}, row, col):
+				sb.WriteStyledString(content+" ", activeStyle)
+			case content == "_":
+				sb.WriteString("  ")
+			default:
+				sb.WriteString(content + " ")
+			}
+		}
+		if row < m.puzzle.NumRows-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// cluesView lists the entries in each direction, scrolled to keep the ones
+// under the cursor in view.
+func (m editorModel) cluesView() string {
+	height := max(m.puzzle.NumRows, 10)
+	list := func(name string, entries []*puzzle.Clue, orientation Orientation) string {
+		current := m.entryAt(entries, m.cursorY, m.cursorX)
+		start := 0
+		if i := slices.Index(entries, current); i >= 0 {
+			start = max(0, min(i-height/2, len(entries)-height))
+		}
+		lines := []string{theme.Get().Foreground(theme.Tertiary()).Render(name)}
+		for _, clue := range entries[start:min(start+height, len(entries))] {
+			text := clue.Clue
+			textStyle := theme.Get()
+			if text == "" {
+				text = m.pattern(clue)
+				textStyle = theme.Get().Foreground(theme.Muted())
+			}
+			line := fmt.Sprintf("%3d %s", clue.Num, truncate(text, 32))
+			switch {
+			case clue == current && orientation == m.orientation:
+				textStyle = theme.Get().Foreground(theme.Primary())
+			case clue == current:
+				textStyle = theme.Get().Foreground(theme.Secondary())
+			}
+			lines = append(lines, textStyle.Render(line))
+		}
+		return theme.Get().Width(38).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		list("Across", m.puzzle.AcrossClues, Horizontal),
+		list("Down", m.puzzle.DownClues, Vertical))
+}
+
+// RunEditor opens puz in the editor, saving it to options.Path whenever
+// asked.
+func RunEditor(puz puzzle.PuzzleDefinition, options EditorOptions) error {
+	_, err := runProgram(initEditorModel(puz, options), options.Terminal)
+	return err
+}
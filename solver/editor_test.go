@@ -0,0 +1,94 @@
+package solver
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+func newTestEditor(rows, cols int, path string) editorModel {
+	theme.Init()
+	puz := puzzle.PuzzleDefinition{NumRows: rows, NumCols: cols, Answer: strings.Repeat("-", rows*cols)}
+	return initEditorModel(puz, EditorOptions{Path: path, Symmetric: true, Unsaved: true})
+}
+
+func clueNumbers(clues []*puzzle.Clue) []int {
+	var nums []int
+	for _, clue := range clues {
+		nums = append(nums, clue.Num)
+	}
+	return nums
+}
+
+func TestEditorRenumbers(t *testing.T) {
+	m := newTestEditor(3, 3, "")
+	if across, down := clueNumbers(m.puzzle.AcrossClues), clueNumbers(m.puzzle.DownClues); !slices.Equal(across, []int{1, 4, 5}) || !slices.Equal(down, []int{1, 2, 3}) {
+		t.Fatalf("open grid numbered %v across and %v down", across, down)
+	}
+	m.puzzle.DownClues[1].Clue = "Middle"
+
+	// The block in the corner is mirrored in the opposite one.
+	m.toggleBlock()
+	if m.puzzle.Answer != "."+"-----"+"--"+"." {
+		t.Fatalf("answer is %q after a block in the corner", m.puzzle.Answer)
+	}
+	if across, down := clueNumbers(m.puzzle.AcrossClues), clueNumbers(m.puzzle.DownClues); !slices.Equal(across, []int{1, 3, 4}) || !slices.Equal(down, []int{1, 2, 3}) {
+		t.Errorf("grid with blocks numbered %v across and %v down", across, down)
+	}
+	if got := m.puzzle.DownClues[0]; got.StartCol != 1 || got.Clue != "Middle" {
+		t.Errorf("1-Down starts at column %d with clue %q, want the middle column's clue kept", got.StartCol, got.Clue)
+	}
+	if m.puzzle.NumClues != len(m.puzzle.AcrossClues)+len(m.puzzle.DownClues) {
+		t.Errorf("NumClues is %d", m.puzzle.NumClues)
+	}
+
+	m.symmetric = false
+	m.toggleBlock()
+	if m.puzzle.Answer != "-"+"-----"+"--"+"." || !m.dirty {
+		t.Errorf("answer is %q after taking the block away, dirty %v", m.puzzle.Answer, m.dirty)
+	}
+}
+
+func TestEditorSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.ipuz")
+	m := newTestEditor(2, 2, path)
+	for i, letter := range "ABCD" {
+		m.set(i/2, i%2, byte(letter))
+	}
+	m.puzzle.AcrossClues[0].Clue = "First"
+	m.save()
+	if m.failed || m.dirty {
+		t.Fatalf("saving failed with %q", m.status)
+	}
+	puz, err := loader.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if puz.Answer != "ABCD" || puz.AcrossClues[0].Clue != "First" || len(puz.DownClues) != 2 {
+		t.Errorf("saved %q with %d down clues and 1-Across %q", puz.Answer, len(puz.DownClues), puz.AcrossClues[0].Clue)
+	}
+}
+
+func TestEditorRefusesToSaveWithoutEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocks.puz")
+	m := newTestEditor(2, 2, path)
+	m.toggleBlock()
+	m.cursorX = 1
+	m.toggleBlock()
+	if m.puzzle.Answer != "...." {
+		t.Fatalf("answer is %q, want only blocks", m.puzzle.Answer)
+	}
+	m.save()
+	if !m.failed || !m.dirty {
+		t.Errorf("saved a grid of blocks: %q", m.status)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("a grid of blocks was written: %v", err)
+	}
+}
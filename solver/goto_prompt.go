@@ -0,0 +1,149 @@
+package solver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+var clueReferencePattern = regexp.MustCompile(`^(\d+)\s*(a|d|across|down)?$`)
+
+type gotoPromptModel struct {
+	acrossClues []*puzzle.Clue
+	downClues   []*puzzle.Clue
+	input       textinput.Model
+	active      bool
+	err         error
+	orientation Orientation
+}
+
+func initGotoPromptModel(puz *puzzle.PuzzleDefinition) gotoPromptModel {
+	input := textinput.New()
+	input.Prompt = "go to clue: "
+	input.Placeholder = "e.g. 12a, 37d or 37"
+	input.CharLimit = 10
+	input.ShowSuggestions = true
+	input.Styles.Focused.Prompt = theme.Get().Foreground(theme.Primary())
+	input.Styles.Focused.Text = theme.Get()
+	input.Styles.Focused.Placeholder = theme.Get().Foreground(theme.Muted())
+	input.Styles.Focused.Suggestion = theme.Get().Foreground(theme.Muted())
+	suggestions := make([]string, 0, len(puz.AcrossClues)+len(puz.DownClues))
+	for _, clue := range puz.AcrossClues {
+		suggestions = append(suggestions, fmt.Sprintf("%da", clue.Num))
+	}
+	for _, clue := range puz.DownClues {
+		suggestions = append(suggestions, fmt.Sprintf("%dd", clue.Num))
+	}
+	input.SetSuggestions(suggestions)
+	return gotoPromptModel{
+		acrossClues: puz.AcrossClues,
+		downClues:   puz.DownClues,
+		input:       input,
+	}
+}
+
+func (m gotoPromptModel) Init() tea.Cmd {
+	return nil
+}
+
+// open shows the prompt. A clue number given without a direction is looked
+// up in orientation first.
+func (m *gotoPromptModel) open(orientation Orientation) tea.Cmd {
+	m.active = true
+	m.orientation = orientation
+	m.err = nil
+	m.input.Reset()
+	return m.input.Focus()
+}
+
+func (m *gotoPromptModel) close() {
+	m.active = false
+	m.input.Blur()
+}
+
+func (m gotoPromptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Back):
+			m.close()
+			return m, nil
+		case key.Matches(msg, keys.SelectClue):
+			clue, orientation, err := m.resolve(m.input.Value())
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.close()
+			return m, func() tea.Msg {
+				return clueSelectedMsg{clue: clue, orientation: orientation}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.err = nil
+	return m, cmd
+}
+
+// resolve looks up the clue referred to by input, which is a clue number
+// optionally followed by a direction. Without a direction the current solving
+// orientation is preferred, falling back to the crossing direction.
+func (m gotoPromptModel) resolve(input string) (*puzzle.Clue, Orientation, error) {
+	matches := clueReferencePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(input)))
+	if matches == nil {
+		return nil, m.orientation, fmt.Errorf("enter a clue number such as 12a or 37d")
+	}
+	num, _ := strconv.Atoi(matches[1])
+
+	var orientations []Orientation
+	switch matches[2] {
+	case "a", "across":
+		orientations = []Orientation{Horizontal}
+	case "d", "down":
+		orientations = []Orientation{Vertical}
+	default:
+		orientations = []Orientation{m.orientation, m.orientation.cross()}
+	}
+
+	for _, orientation := range orientations {
+		clues := m.acrossClues
+		if orientation == Vertical {
+			clues = m.downClues
+		}
+		for _, clue := range clues {
+			if clue.Num == num {
+				return clue, orientation, nil
+			}
+		}
+	}
+
+	if len(orientations) == 1 {
+		return nil, orientations[0], fmt.Errorf("there is no %d %s", num, orientationName(orientations[0]))
+	}
+	return nil, m.orientation, fmt.Errorf("there is no clue numbered %d", num)
+}
+
+func (m gotoPromptModel) View() string {
+	var hint string
+	if m.err != nil {
+		hint = theme.Get().Foreground(theme.Red()).Render(m.err.Error())
+	} else if m.input.Value() != "" {
+		clue, orientation, err := m.resolve(m.input.Value())
+		if err != nil {
+			hint = theme.Get().Foreground(theme.Red()).Render(err.Error())
+		} else {
+			hint = theme.Get().Foreground(theme.Secondary()).Render(
+				fmt.Sprintf("%d %s: %s", clue.Num, orientationName(orientation), clue.Clue))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.input.View(), hint)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
 	prefs "github.com/tylerwgrass/cruciterm/preferences"
 	"github.com/tylerwgrass/cruciterm/puzzle"
 	"github.com/tylerwgrass/cruciterm/theme"
@@ -25,6 +26,27 @@ const (
 	Vertical
 )
 
+func (o Orientation) cross() Orientation {
+	if o == Horizontal {
+		return Vertical
+	}
+	return Horizontal
+}
+
+func orientationName(o Orientation) string {
+	if o == Vertical {
+		return "Down"
+	}
+	return "Across"
+}
+
+func orientationOf(clue *puzzle.Clue) Orientation {
+	if clue.Direction == puzzle.Down {
+		return Vertical
+	}
+	return Horizontal
+}
+
 type gridModel struct {
 	navigator      *Navigator
 	solution       string
@@ -32,9 +54,30 @@ type gridModel struct {
 	cursorX        int
 	cursorY        int
 	navOrientation Orientation
+	referenceStack []referenceOrigin
+	// locked puzzles have a scrambled solution, so squares cannot be checked
+	// or revealed.
+	locked  bool
+	checks  int
+	reveals int
+	// corrections counts filled squares that were overwritten or cleared.
+	corrections  int
+	lettersTyped int
+	// peers are the cursors of other players in co-op.
+	peers []peerCursor
+	prefs prefs.Preferences
+}
+
+// referenceOrigin remembers where a jump to a cross-referenced clue started
+// so that the cursor can be returned there.
+type referenceOrigin struct {
+	clue        *puzzle.Clue
+	cursorX     int
+	cursorY     int
+	orientation Orientation
 }
 
-func initGridModel(puz *puzzle.PuzzleDefinition) gridModel {
+func initGridModel(puz *puzzle.PuzzleDefinition, preferences prefs.Preferences) gridModel {
 	basicGrid := make([][]string, puz.NumRows)
 	var initialX int
 	var initialY int
@@ -54,10 +97,7 @@ func initGridModel(puz *puzzle.PuzzleDefinition) gridModel {
 			}
 		}
 	}
-	navigator := NewNavigator(basicGrid, puz)
-	grid := navigator.grid
-	currentAcrossClue = (*grid)[initialY][initialX].acrossClue
-	currentDownClue = (*grid)[initialY][initialX].downClue
+	navigator := NewNavigator(basicGrid, puz, preferences)
 	return gridModel{
 		navigator:      navigator,
 		solved:         solved,
@@ -65,6 +105,8 @@ func initGridModel(puz *puzzle.PuzzleDefinition) gridModel {
 		cursorX:        initialX,
 		cursorY:        initialY,
 		navOrientation: Horizontal,
+		prefs:          preferences,
+		locked:         puz.Locked,
 	}
 }
 
@@ -85,12 +127,13 @@ func (m gridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		halters := make([]IHalter, 0, 1)
 		defaultHalter := makeHalter(ValidSquare, false)
 		halters = append(halters, defaultHalter)
-		if prefs.GetBool(prefs.JumpToEmptySquare) {
+		if m.prefs.GetBool(prefs.JumpToEmptySquare) {
 			halters = append(halters, makeHalter(EmptySquare, true))
 		}
 
 		if ok, _ := regexp.MatchString(`^[a-zA-Z0-9]$`, msg.String()); ok {
-			(*m.navigator.grid)[m.cursorY][m.cursorX].content = strings.ToUpper(string(msg.String()[0]))
+			m.lettersTyped++
+			m.setContent(m.cursorY, m.cursorX, strings.ToUpper(string(msg.String()[0])))
 			navStates = m.navigator.
 				withOrientation(m.navOrientation).
 				withHalters(halters).
@@ -100,7 +143,7 @@ func (m gridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			didWrap = slices.ContainsFunc(navStates, func(ns NavigationState) bool {
 				return ns.didWrap
 			})
-			if didWrap && prefs.GetBool(prefs.SwapCursorOnGridWrap) && endNavState.haltedOnMatch {
+			if didWrap && m.prefs.GetBool(prefs.SwapCursorOnGridWrap) && endNavState.haltedOnMatch {
 				m.changeNavOrientation()
 			}
 			break
@@ -108,7 +151,7 @@ func (m gridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch {
 		case key.Matches(msg, keys.Delete):
-			(*m.navigator.grid)[m.cursorY][m.cursorX].content = "-"
+			m.setContent(m.cursorY, m.cursorX, "-")
 			navStates = m.navigator.
 				withOrientation(m.navOrientation).
 				withMoveDirection(Reverse).
@@ -117,10 +160,26 @@ func (m gridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				advanceCursor(m.cursorX, m.cursorY)
 		case key.Matches(msg, keys.ToggleDirection):
 			m.changeNavOrientation()
+		case key.Matches(msg, keys.CheckClue):
+			m.checkActiveClue()
+		case key.Matches(msg, keys.RevealSquare):
+			m.revealSquare(m.cursorY, m.cursorX)
+		case key.Matches(msg, keys.JumpToWrong):
+			m.jumpToWrongSquare()
+			navStates[0].row, navStates[0].col = m.cursorY, m.cursorX
+		case key.Matches(msg, keys.JumpToUnchecked):
+			m.jumpToUncheckedEntry()
+			navStates[0].row, navStates[0].col = m.cursorY, m.cursorX
+		case key.Matches(msg, keys.FollowReference):
+			m.followReference()
+			navStates[0].row, navStates[0].col = m.cursorY, m.cursorX
+		case key.Matches(msg, keys.ReturnReference):
+			m.returnFromReference()
+			navStates[0].row, navStates[0].col = m.cursorY, m.cursorX
 		case key.Matches(msg, keys.PrevClue):
 			halters = make([]IHalter, 0, 2)
 			halters = append(halters, makeHalter(ClueChange, false))
-			if prefs.GetBool(prefs.JumpToEmptySquare) {
+			if m.prefs.GetBool(prefs.JumpToEmptySquare) {
 				halters = append(halters, makeHalter(EmptySquare, true))
 			}
 			navStates = m.navigator.
@@ -131,7 +190,7 @@ func (m gridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, keys.NextClue):
 			halters = make([]IHalter, 0, 2)
 			halters = append(halters, makeHalter(ClueChange, false))
-			if prefs.GetBool(prefs.JumpToEmptySquare) {
+			if m.prefs.GetBool(prefs.JumpToEmptySquare) {
 				halters = append(halters, makeHalter(EmptySquare, true))
 			}
 			navStates = m.navigator.
@@ -139,7 +198,7 @@ func (m gridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				withHalters(halters).
 				advanceCursor(m.cursorX, m.cursorY)
 		case key.Matches(msg, keys.Up):
-			if prefs.GetBool(prefs.SwapCursorOnDirectionChange) && m.navOrientation != Vertical {
+			if m.prefs.GetBool(prefs.SwapCursorOnDirectionChange) && m.navOrientation != Vertical {
 				m.changeNavOrientation()
 				break
 			}
@@ -149,7 +208,7 @@ func (m gridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				withIterMode(Cardinal).
 				advanceCursor(m.cursorX, m.cursorY)
 		case key.Matches(msg, keys.Down):
-			if prefs.GetBool(prefs.SwapCursorOnDirectionChange) && m.navOrientation != Vertical {
+			if m.prefs.GetBool(prefs.SwapCursorOnDirectionChange) && m.navOrientation != Vertical {
 				m.changeNavOrientation()
 				break
 			}
@@ -158,7 +217,7 @@ func (m gridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				withIterMode(Cardinal).
 				advanceCursor(m.cursorX, m.cursorY)
 		case key.Matches(msg, keys.Left):
-			if prefs.GetBool(prefs.SwapCursorOnDirectionChange) && m.navOrientation != Horizontal {
+			if m.prefs.GetBool(prefs.SwapCursorOnDirectionChange) && m.navOrientation != Horizontal {
 				m.changeNavOrientation()
 				break
 			}
@@ -167,7 +226,7 @@ func (m gridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				withIterMode(Cardinal).
 				advanceCursor(m.cursorX, m.cursorY)
 		case key.Matches(msg, keys.Right):
-			if prefs.GetBool(prefs.SwapCursorOnDirectionChange) && m.navOrientation != Horizontal {
+			if m.prefs.GetBool(prefs.SwapCursorOnDirectionChange) && m.navOrientation != Horizontal {
 				m.changeNavOrientation()
 				break
 			}
@@ -180,19 +239,20 @@ func (m gridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		didWrap = slices.ContainsFunc(navStates, func(ns NavigationState) bool {
 			return ns.didWrap
 		})
-		if didWrap && prefs.GetBool(prefs.SwapCursorOnGridWrap) && endNavState.haltedOnMatch {
+		if didWrap && m.prefs.GetBool(prefs.SwapCursorOnGridWrap) && endNavState.haltedOnMatch {
 			m.changeNavOrientation()
 		}
 	}
 	m.navigator.resetNavigatorOptions()
 	m.validateSolution()
-	currentAcrossClue = (*m.navigator.grid)[m.cursorY][m.cursorX].acrossClue
-	currentDownClue = (*m.navigator.grid)[m.cursorY][m.cursorX].downClue
 	return m, nil
 }
 
 func (m gridModel) View() string {
 	activeClueStyle := theme.Get().Foreground(theme.Primary())
+	referencedClueStyle := theme.Get().Foreground(theme.Tertiary())
+	wrongStyle := theme.Get().Foreground(theme.Red())
+	revealedStyle := theme.Get().Foreground(theme.Green())
 	sb := theme.NewThemedStringBuilder(theme.Get())
 	var cursor string
 	if m.navOrientation == Horizontal {
@@ -201,6 +261,7 @@ func (m gridModel) View() string {
 		cursor = "v"
 	}
 
+	references := m.activeClue().References
 	for i, row := range *m.navigator.grid {
 		sb.WriteString(" ")
 		for j, cell := range row {
@@ -208,18 +269,37 @@ func (m gridModel) View() string {
 				sb.WriteStyledString(cursor+" ", activeClueStyle)
 				continue
 			}
+			if peer := slices.IndexFunc(m.peers, func(p peerCursor) bool { return p.row == i && p.col == j }); peer != -1 && cell.content != "." {
+				content := cell.content
+				if content == "-" {
+					content = "_"
+				}
+				sb.WriteStyledString(content, theme.Get().Background(peerColor(m.peers[peer].id)).Foreground(theme.Background()))
+				sb.WriteString(" ")
+				continue
+			}
+			var highlightStyle *lipgloss.Style
+			if m.isCellInActiveClue(i, j) {
+				highlightStyle = &activeClueStyle
+			} else if isCellInAnyClue(references, i, j) {
+				highlightStyle = &referencedClueStyle
+			}
 			switch cell.content {
 			case ".":
 				sb.WriteString("■ ")
 			case "-":
-				if m.isCellInActiveClue(i, j) {
-					sb.WriteStyledString("_ ", activeClueStyle)
+				if highlightStyle != nil {
+					sb.WriteStyledString("_ ", *highlightStyle)
 				} else {
 					sb.WriteString("  ")
 				}
 			default:
-				if m.isCellInActiveClue(i, j) {
-					sb.WriteStyledString(cell.content+" ", activeClueStyle)
+				if cell.wrong {
+					sb.WriteStyledString(cell.content+" ", wrongStyle)
+				} else if cell.revealed {
+					sb.WriteStyledString(cell.content+" ", revealedStyle)
+				} else if highlightStyle != nil {
+					sb.WriteStyledString(cell.content+" ", *highlightStyle)
 				} else {
 					sb.WriteString(cell.content + " ")
 				}
@@ -234,14 +314,196 @@ func (m gridModel) View() string {
 }
 
 func (m gridModel) isCellInActiveClue(row, col int) bool {
-	return (m.navOrientation == Horizontal &&
-		col >= currentAcrossClue.StartCol &&
-		col <= currentAcrossClue.EndCol &&
-		row == m.cursorY) ||
-		(m.navOrientation == Vertical &&
-			row >= currentDownClue.StartRow &&
-			row <= currentDownClue.EndRow &&
-			col == m.cursorX)
+	return isCellInAnyClue([]*puzzle.Clue{m.activeClue()}, row, col)
+}
+
+func isCellInAnyClue(clues []*puzzle.Clue, row, col int) bool {
+	for _, clue := range clues {
+		if row >= clue.StartRow && row <= clue.EndRow && col >= clue.StartCol && col <= clue.EndCol {
+			return true
+		}
+	}
+	return false
+}
+
+// currentClues are the across and down clues under the cursor.
+func (m gridModel) currentClues() (*puzzle.Clue, *puzzle.Clue) {
+	cell := (*m.navigator.grid)[m.cursorY][m.cursorX]
+	return cell.acrossClue, cell.downClue
+}
+
+// activeClue is the clue under the cursor in the solving orientation.
+func (m gridModel) activeClue() *puzzle.Clue {
+	cell := (*m.navigator.grid)[m.cursorY][m.cursorX]
+	if m.navOrientation == Vertical {
+		return cell.downClue
+	}
+	return cell.acrossClue
+}
+
+// followReference jumps to a clue referenced by the active clue. Repeating
+// it from a referenced clue cycles through the other references of the clue
+// the jump started from.
+func (m *gridModel) followReference() {
+	if n := len(m.referenceStack); n > 0 {
+		origin := m.referenceStack[n-1]
+		if i := slices.Index(origin.clue.References, m.activeClue()); i != -1 && len(origin.clue.References) > 1 {
+			next := origin.clue.References[(i+1)%len(origin.clue.References)]
+			m.jumpToClue(next, orientationOf(next))
+			return
+		}
+	}
+
+	clue := m.activeClue()
+	if len(clue.References) == 0 {
+		return
+	}
+	m.referenceStack = append(m.referenceStack, referenceOrigin{
+		clue:        clue,
+		cursorX:     m.cursorX,
+		cursorY:     m.cursorY,
+		orientation: m.navOrientation,
+	})
+	m.jumpToClue(clue.References[0], orientationOf(clue.References[0]))
+}
+
+func (m *gridModel) returnFromReference() {
+	n := len(m.referenceStack)
+	if n == 0 {
+		return
+	}
+	origin := m.referenceStack[n-1]
+	m.referenceStack = m.referenceStack[:n-1]
+	m.cursorX, m.cursorY = origin.cursorX, origin.cursorY
+	m.navOrientation = origin.orientation
+}
+
+// jumpToClue moves the cursor to the start of clue, or to its first empty
+// square when JumpToEmptySquare is set, and solves in the given orientation.
+func (m *gridModel) jumpToClue(clue *puzzle.Clue, orientation Orientation) {
+	m.navOrientation = orientation
+	m.cursorX, m.cursorY = clue.StartCol, clue.StartRow
+	if m.prefs.GetBool(prefs.JumpToEmptySquare) {
+		grid := *m.navigator.grid
+	search:
+		for row := clue.StartRow; row <= clue.EndRow; row++ {
+			for col := clue.StartCol; col <= clue.EndCol; col++ {
+				if grid[row][col].content == "-" {
+					m.cursorX, m.cursorY = col, row
+					break search
+				}
+			}
+		}
+	}
+}
+
+// setContent changes a square as the solver typed it, counting overwriting
+// or clearing a letter as a correction.
+func (m *gridModel) setContent(row, col int, content string) {
+	cell := (*m.navigator.grid)[row][col]
+	if cell.content != content && cell.content != "-" {
+		m.corrections++
+	}
+	m.fillSquare(row, col, content)
+}
+
+// fillSquare changes a square, clearing any check or reveal marking.
+func (m *gridModel) fillSquare(row, col int, content string) {
+	cell := &(*m.navigator.grid)[row][col]
+	if cell.content == content {
+		return
+	}
+	cell.corrected = cell.corrected || cell.wrong
+	cell.wrong, cell.revealed, cell.checked = false, false, false
+	cell.content = content
+}
+
+// checkActiveClue marks the incorrect letters in the active clue.
+func (m *gridModel) checkActiveClue() {
+	if m.locked {
+		return
+	}
+	m.checks++
+	clue := m.activeClue()
+	grid := *m.navigator.grid
+	for row := clue.StartRow; row <= clue.EndRow; row++ {
+		for col := clue.StartCol; col <= clue.EndCol; col++ {
+			cell := &grid[row][col]
+			cell.checked = cell.content != "-"
+			cell.wrong = cell.checked && cell.content != m.answerAt(row, col)
+		}
+	}
+}
+
+func (m *gridModel) revealSquare(row, col int) {
+	if m.locked || (*m.navigator.grid)[row][col].content == m.answerAt(row, col) {
+		return
+	}
+	m.reveals++
+	cell := &(*m.navigator.grid)[row][col]
+	cell.content, cell.wrong, cell.revealed, cell.checked = m.answerAt(row, col), false, true, true
+}
+
+// filledIncorrectly reports a grid with every square filled that still does
+// not match the solution.
+func (m gridModel) filledIncorrectly() bool {
+	return !m.solved && m.percentFilled() == 100
+}
+
+// wrongSquares counts the incorrect letters and finds the first of them in
+// reading order.
+func (m gridModel) wrongSquares() (count, firstRow, firstCol int) {
+	firstRow, firstCol = -1, -1
+	for i, row := range *m.navigator.grid {
+		for j, cell := range row {
+			if cell.content == "." || cell.content == "-" || cell.content == m.answerAt(i, j) {
+				continue
+			}
+			if count == 0 {
+				firstRow, firstCol = i, j
+			}
+			count++
+		}
+	}
+	return count, firstRow, firstCol
+}
+
+// jumpToWrongSquare moves the cursor to the first incorrect letter of a full
+// grid and marks it. It counts as a check.
+func (m *gridModel) jumpToWrongSquare() {
+	if m.locked || !m.filledIncorrectly() {
+		return
+	}
+	_, row, col := m.wrongSquares()
+	m.checks++
+	m.cursorY, m.cursorX = row, col
+	cell := &(*m.navigator.grid)[row][col]
+	cell.wrong, cell.checked = true, true
+}
+
+// jumpToUncheckedEntry moves the cursor to the first square no check or
+// reveal has confirmed, in the Across entry holding it if there is one. That
+// is the first entry of all that hasn't been checked in full. Unlike checking,
+// this gives nothing away, so it doesn't count as a check.
+func (m *gridModel) jumpToUncheckedEntry() {
+	for i, row := range *m.navigator.grid {
+		for j, cell := range row {
+			if cell.content == "." || cell.checked {
+				continue
+			}
+			m.cursorY, m.cursorX = i, j
+			if cell.acrossClue != nil && cell.acrossClue.StartCol != cell.acrossClue.EndCol {
+				m.navOrientation = Horizontal
+			} else {
+				m.navOrientation = Vertical
+			}
+			return
+		}
+	}
+}
+
+func (m gridModel) answerAt(row, col int) string {
+	return string(m.solution[row*len((*m.navigator.grid)[0])+col])
 }
 
 func (m *gridModel) changeNavOrientation() {
@@ -266,3 +528,86 @@ func (m *gridModel) validateSolution() {
 	}
 	m.solved = true
 }
+
+// fillPattern renders the current contents of clue's squares, using an
+// underscore for each empty square.
+func (grid NavigationGrid) fillPattern(clue *puzzle.Clue) string {
+	var sb strings.Builder
+	for row := clue.StartRow; row <= clue.EndRow; row++ {
+		for col := clue.StartCol; col <= clue.EndCol; col++ {
+			if grid[row][col].content == "-" {
+				sb.WriteString("_")
+			} else {
+				sb.WriteString(grid[row][col].content)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// percentFilled is the share of white squares that have been filled in.
+func (m gridModel) percentFilled() int {
+	filled, total := 0, 0
+	for _, row := range *m.navigator.grid {
+		for _, cell := range row {
+			if cell.content == "." {
+				continue
+			}
+			total++
+			if cell.content != "-" {
+				filled++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return filled * 100 / total
+}
+
+// numCells counts the squares that take a letter.
+func (m gridModel) numCells() int {
+	total := 0
+	for _, row := range *m.navigator.grid {
+		for _, cell := range row {
+			if cell.content != "." {
+				total++
+			}
+		}
+	}
+	return total
+}
+
+// shareGrid draws the grid as emoji: green squares were solved unaided,
+// yellow ones were fixed after a check and blue ones were revealed.
+func (m gridModel) shareGrid() string {
+	var sb strings.Builder
+	for _, row := range *m.navigator.grid {
+		for _, cell := range row {
+			switch {
+			case cell.content == ".":
+				sb.WriteString("⬛")
+			case cell.revealed:
+				sb.WriteString("🟦")
+			case cell.corrected || cell.wrong:
+				sb.WriteString("🟨")
+			default:
+				sb.WriteString("🟩")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// state serializes the grid contents in the same form as
+// puzzle.PuzzleDefinition.CurrentState.
+func (m gridModel) state() string {
+	var sb strings.Builder
+	for _, row := range *m.navigator.grid {
+		for _, cell := range row {
+			sb.WriteString(cell.content)
+		}
+	}
+	return sb.String()
+}
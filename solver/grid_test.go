@@ -0,0 +1,60 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+func TestJumpToUncheckedEntry(t *testing.T) {
+	theme.Init()
+	puz := newTestPuzzle("CAT"+"AR."+"TEN", 3, 3, "")
+	grid := initGridModel(puz, nil)
+	for i, letter := range "CATAR.TEX" {
+		if letter != '.' {
+			grid.setContent(i/3, i%3, string(letter))
+		}
+	}
+
+	tests := []struct {
+		name        string
+		checkRow    int
+		checkCol    int
+		orientation Orientation
+		row, col    int
+	}{
+		{"nothing checked", -1, -1, Horizontal, 0, 0},
+		{"first across checked", 0, 0, Horizontal, 1, 0},
+		{"second across checked", 1, 0, Horizontal, 2, 0},
+		{"every square checked", 2, 0, Horizontal, 1, 1},
+	}
+	for _, test := range tests {
+		if test.checkRow >= 0 {
+			grid.cursorY, grid.cursorX, grid.navOrientation = test.checkRow, test.checkCol, Horizontal
+			grid.checkActiveClue()
+		}
+		grid.cursorY, grid.cursorX = 1, 1
+		grid.jumpToUncheckedEntry()
+		if grid.cursorY != test.row || grid.cursorX != test.col || grid.navOrientation != test.orientation {
+			t.Errorf("%s: cursor at %d,%d going %v, want %d,%d going %v", test.name,
+				grid.cursorY, grid.cursorX, grid.navOrientation, test.row, test.col, test.orientation)
+		}
+	}
+
+	// Changing the wrong letter in 5 Across unchecks it.
+	grid.setContent(2, 2, "Y")
+	grid.jumpToUncheckedEntry()
+	if grid.cursorY != 2 || grid.cursorX != 2 {
+		t.Errorf("after a change: cursor at %d,%d, want 2,2", grid.cursorY, grid.cursorX)
+	}
+	grid.cursorY, grid.cursorX = 2, 2
+	grid.revealSquare(2, 2)
+	grid.cursorY, grid.cursorX = 0, 0
+	grid.jumpToUncheckedEntry()
+	if grid.cursorY != 0 || grid.cursorX != 0 {
+		t.Errorf("with every square checked the cursor moved to %d,%d", grid.cursorY, grid.cursorX)
+	}
+	if grid.checks != 3 {
+		t.Errorf("checks = %d, want 3; jumping must not count as one", grid.checks)
+	}
+}
@@ -1,6 +1,13 @@
 package solver
 
-import "github.com/charmbracelet/bubbles/v2/key"
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+)
 
 type keyMap struct {
 	Up               key.Binding
@@ -14,6 +21,46 @@ type keyMap struct {
 	ToggleDirection  key.Binding
 	TogglePreference key.Binding
 	ViewPreferences  key.Binding
+	FocusClues       key.Binding
+	GotoClue         key.Binding
+	SearchClues      key.Binding
+	FollowReference  key.Binding
+	ReturnReference  key.Binding
+	ViewNotes        key.Binding
+	PageUp           key.Binding
+	PageDown         key.Binding
+	ListTop          key.Binding
+	ListBottom       key.Binding
+	SelectClue       key.Binding
+	Back             key.Binding
+	NextTab          key.Binding
+	PrevTab          key.Binding
+	OpenPuzzle       key.Binding
+	MarkPuzzle       key.Binding
+	SortLibrary      key.Binding
+	ReverseSort      key.Binding
+	FilterStatus     key.Binding
+	CheckClue        key.Binding
+	RevealSquare     key.Binding
+	ViewStats        key.Binding
+	ViewSummary      key.Binding
+	SaveFill         key.Binding
+	ExportShare      key.Binding
+	LeaveSolver      key.Binding
+	JumpToWrong      key.Binding
+	JumpToUnchecked  key.Binding
+	PausePlayback    key.Binding
+	SeekBackward     key.Binding
+	SeekForward      key.Binding
+	FasterPlayback   key.Binding
+	SlowerPlayback   key.Binding
+	FollowPlayer     key.Binding
+	ToggleBlock      key.Binding
+	ToggleSymmetry   key.Binding
+	EditClue         key.Binding
+	ResizeGrid       key.Binding
+	SavePuzzle       key.Binding
+	Autofill         key.Binding
 }
 
 var keys = keyMap{
@@ -52,6 +99,176 @@ var keys = keyMap{
 		key.WithKeys("ctrl+p"),
 		key.WithHelp("ctrl+p", "change preferences"),
 	),
+	// Not tab, which has always moved to the next clue from the grid. Once
+	// the list has focus, tab switches between Across and Down.
+	FocusClues: key.NewBinding(
+		key.WithKeys("ctrl+l"),
+		key.WithHelp("ctrl+l", "focus clue list"),
+	),
+	GotoClue: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "go to clue"),
+	),
+	SearchClues: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("ctrl+f", "search clues"),
+	),
+	FollowReference: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "go to referenced clue"),
+	),
+	ReturnReference: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("ctrl+o", "return from reference"),
+	),
+	ViewNotes: key.NewBinding(
+		key.WithKeys("ctrl+n"),
+		key.WithHelp("ctrl+n", "view notes"),
+	),
+	// Clue list keys
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("pgup", "page up"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("pgdown", "page down"),
+	),
+	ListTop: key.NewBinding(
+		key.WithKeys("home"),
+		key.WithHelp("home", "first clue"),
+	),
+	ListBottom: key.NewBinding(
+		key.WithKeys("end"),
+		key.WithHelp("end", "last clue"),
+	),
+	SelectClue: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "go to clue"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "back to grid"),
+	),
+	// Grid action keys
+	CheckClue: key.NewBinding(
+		key.WithKeys("ctrl+k"),
+		key.WithHelp("ctrl+k", "check clue"),
+	),
+	RevealSquare: key.NewBinding(
+		key.WithKeys("ctrl+e"),
+		key.WithHelp("ctrl+e", "reveal square"),
+	),
+	JumpToWrong: key.NewBinding(
+		key.WithKeys("ctrl+w"),
+		key.WithHelp("ctrl+w", "jump to wrong square"),
+	),
+	JumpToUnchecked: key.NewBinding(
+		key.WithKeys("ctrl+u"),
+		key.WithHelp("ctrl+u", "jump to unchecked entry"),
+	),
+	ViewSummary: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "solve summary"),
+	),
+	// Tab keys
+	NextTab: key.NewBinding(
+		key.WithKeys("ctrl+pgdown", "alt+]"),
+		key.WithHelp("alt+]", "next puzzle"),
+	),
+	PrevTab: key.NewBinding(
+		key.WithKeys("ctrl+pgup", "alt+["),
+		key.WithHelp("alt+[", "previous puzzle"),
+	),
+	// Library keys
+	OpenPuzzle: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "open puzzle"),
+	),
+	MarkPuzzle: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "mark to open in tabs"),
+	),
+	SortLibrary: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "change sort"),
+	),
+	ReverseSort: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "reverse sort"),
+	),
+	FilterStatus: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "filter by status"),
+	),
+	ViewStats: key.NewBinding(
+		key.WithKeys("ctrl+a"),
+		key.WithHelp("ctrl+a", "solve stats"),
+	),
+	// Completion View keys
+	SaveFill: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "save to file"),
+	),
+	ExportShare: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "export share text"),
+	),
+	LeaveSolver: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "quit"),
+	),
+	// Playback keys
+	PausePlayback: key.NewBinding(
+		key.WithKeys("space"),
+		key.WithHelp("space", "play/pause"),
+	),
+	SeekBackward: key.NewBinding(
+		key.WithKeys("left"),
+		key.WithHelp("←", "seek back"),
+	),
+	SeekForward: key.NewBinding(
+		key.WithKeys("right"),
+		key.WithHelp("→", "seek forward"),
+	),
+	FasterPlayback: key.NewBinding(
+		key.WithKeys("up", "+", "="),
+		key.WithHelp("↑", "faster"),
+	),
+	SlowerPlayback: key.NewBinding(
+		key.WithKeys("down", "-"),
+		key.WithHelp("↓", "slower"),
+	),
+	// Spectator keys
+	FollowPlayer: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "follow next player"),
+	),
+	// Editor keys
+	ToggleBlock: key.NewBinding(
+		key.WithKeys("."),
+		key.WithHelp(".", "toggle block"),
+	),
+	ToggleSymmetry: key.NewBinding(
+		key.WithKeys("ctrl+y"),
+		key.WithHelp("ctrl+y", "toggle symmetry"),
+	),
+	EditClue: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "write clue"),
+	),
+	ResizeGrid: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "resize grid"),
+	),
+	SavePuzzle: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "save"),
+	),
+	Autofill: key.NewBinding(
+		key.WithKeys("ctrl+a"),
+		key.WithHelp("ctrl+a", "autofill"),
+	),
 	// Preferences View keys
 	TogglePreference: key.NewBinding(
 		key.WithKeys("space"),
@@ -66,7 +283,206 @@ func (k keyMap) ShortHelp() []key.Binding {
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.NextClue, k.PrevClue},
-		{k.ToggleDirection},
-		{k.ViewPreferences, k.Quit},
+		{k.ToggleDirection, k.FocusClues},
+		{k.GotoClue, k.SearchClues},
+		{k.FollowReference, k.ReturnReference},
+		{k.CheckClue, k.RevealSquare},
+		{k.JumpToWrong, k.JumpToUnchecked},
+		{k.ViewNotes, k.ViewPreferences},
+		{k.ViewSummary, k.Quit},
+	}
+}
+
+// tabbedKeyMap adds the tab bindings to the help when several puzzles are
+// open.
+type tabbedKeyMap keyMap
+
+func (k tabbedKeyMap) ShortHelp() []key.Binding {
+	return append(keyMap(k).ShortHelp(), k.NextTab)
+}
+
+func (k tabbedKeyMap) FullHelp() [][]key.Binding {
+	return append(keyMap(k).FullHelp(), []key.Binding{k.NextTab, k.PrevTab})
+}
+
+// libraryKeyMap describes the bindings available in the library.
+type libraryKeyMap keyMap
+
+func (k libraryKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.OpenPuzzle, k.MarkPuzzle, k.Quit}
+}
+
+func (k libraryKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.OpenPuzzle, k.MarkPuzzle},
+		{k.SortLibrary, k.ReverseSort},
+		{k.FilterStatus, k.ViewStats},
+		{k.Quit},
+	}
+}
+
+// completionKeyMap describes the bindings available on the completion screen.
+type completionKeyMap keyMap
+
+func (k completionKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.SaveFill, k.ExportShare, k.LeaveSolver, k.Back}
+}
+
+func (k completionKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// playbackKeyMap describes the bindings available while playing a replay.
+type playbackKeyMap keyMap
+
+func (k playbackKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.PausePlayback,
+		pairedHelp(k.SeekBackward, k.SeekForward, "seek"),
+		pairedHelp(k.FasterPlayback, k.SlowerPlayback, "speed"),
+		k.ListTop,
+		k.Back,
+	}
+}
+
+// pairedHelp shows two opposite bindings as one entry in the help, such as
+// "←/→ seek", using whatever keys they are bound to.
+func pairedHelp(a, b key.Binding, desc string) key.Binding {
+	return key.NewBinding(
+		key.WithKeys(append(a.Keys(), b.Keys()...)...),
+		key.WithHelp(a.Help().Key+"/"+b.Help().Key, desc),
+	)
+}
+
+func (k playbackKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// spectatorKeyMap describes the bindings available while watching a co-op
+// puzzle.
+type spectatorKeyMap keyMap
+
+func (k spectatorKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.FollowPlayer, k.ViewNotes, k.ViewSummary, k.Quit}
+}
+
+func (k spectatorKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{k.FollowPlayer}, {k.ViewNotes, k.ViewSummary}, {k.Quit}}
+}
+
+// editorKeyMap describes the bindings available in the editor.
+type editorKeyMap keyMap
+
+func (k editorKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.ToggleBlock, k.EditClue, k.SavePuzzle, k.Quit}
+}
+
+func (k editorKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.ToggleBlock, k.ToggleSymmetry},
+		{k.ToggleDirection, k.NextClue},
+		{k.EditClue, k.ResizeGrid},
+		{k.Autofill, k.SavePuzzle, k.Quit},
+	}
+}
+
+// clueListKeyMap describes the bindings available while the clue list has
+// focus.
+type clueListKeyMap keyMap
+
+func (k clueListKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.SelectClue, k.Back, k.Quit}
+}
+
+func (k clueListKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.PageUp, k.PageDown},
+		{k.ListTop, k.ListBottom},
+		{k.SelectClue, k.Back},
+		{k.Quit},
+	}
+}
+
+// bindings names every configurable binding, as used in keymap files.
+func (k *keyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"Up":               &k.Up,
+		"Down":             &k.Down,
+		"Left":             &k.Left,
+		"Right":            &k.Right,
+		"Delete":           &k.Delete,
+		"Quit":             &k.Quit,
+		"NextClue":         &k.NextClue,
+		"PrevClue":         &k.PrevClue,
+		"ToggleDirection":  &k.ToggleDirection,
+		"TogglePreference": &k.TogglePreference,
+		"ViewPreferences":  &k.ViewPreferences,
+		"FocusClues":       &k.FocusClues,
+		"GotoClue":         &k.GotoClue,
+		"SearchClues":      &k.SearchClues,
+		"FollowReference":  &k.FollowReference,
+		"ReturnReference":  &k.ReturnReference,
+		"ViewNotes":        &k.ViewNotes,
+		"PageUp":           &k.PageUp,
+		"PageDown":         &k.PageDown,
+		"ListTop":          &k.ListTop,
+		"ListBottom":       &k.ListBottom,
+		"SelectClue":       &k.SelectClue,
+		"Back":             &k.Back,
+		"NextTab":          &k.NextTab,
+		"PrevTab":          &k.PrevTab,
+		"OpenPuzzle":       &k.OpenPuzzle,
+		"MarkPuzzle":       &k.MarkPuzzle,
+		"SortLibrary":      &k.SortLibrary,
+		"ReverseSort":      &k.ReverseSort,
+		"FilterStatus":     &k.FilterStatus,
+		"CheckClue":        &k.CheckClue,
+		"RevealSquare":     &k.RevealSquare,
+		"ViewStats":        &k.ViewStats,
+		"ViewSummary":      &k.ViewSummary,
+		"SaveFill":         &k.SaveFill,
+		"ExportShare":      &k.ExportShare,
+		"LeaveSolver":      &k.LeaveSolver,
+		"JumpToWrong":      &k.JumpToWrong,
+		"JumpToUnchecked":  &k.JumpToUnchecked,
+		"PausePlayback":    &k.PausePlayback,
+		"SeekBackward":     &k.SeekBackward,
+		"SeekForward":      &k.SeekForward,
+		"FasterPlayback":   &k.FasterPlayback,
+		"SlowerPlayback":   &k.SlowerPlayback,
+		"FollowPlayer":     &k.FollowPlayer,
+		"ToggleBlock":      &k.ToggleBlock,
+		"ToggleSymmetry":   &k.ToggleSymmetry,
+		"EditClue":         &k.EditClue,
+		"ResizeGrid":       &k.ResizeGrid,
+		"SavePuzzle":       &k.SavePuzzle,
+		"Autofill":         &k.Autofill,
+	}
+}
+
+// LoadKeyMap overrides key bindings from a JSON file mapping binding names
+// to the keys that trigger them, e.g. {"NextClue": ["tab", "enter"]}.
+func LoadKeyMap(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var overrides map[string][]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("could not parse keymap %s: %w", path, err)
+	}
+
+	bindings := keys.bindings()
+	for name, boundKeys := range overrides {
+		binding, ok := bindings[name]
+		if !ok {
+			return fmt.Errorf("unknown key binding %q in %s", name, path)
+		}
+		if len(boundKeys) == 0 {
+			return fmt.Errorf("no keys given for %q in %s", name, path)
+		}
+		binding.SetKeys(boundKeys...)
+		binding.SetHelp(strings.Join(boundKeys, "/"), binding.Help().Desc)
 	}
+	return nil
 }
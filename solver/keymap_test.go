@@ -0,0 +1,90 @@
+package solver
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/v2/help"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+func TestPlaybackHelpFollowsKeyMap(t *testing.T) {
+	defaults := keys
+	t.Cleanup(func() { keys = defaults })
+
+	helpKeys := func() []string {
+		var shown []string
+		for _, binding := range playbackKeyMap(keys).ShortHelp() {
+			shown = append(shown, binding.Help().Key)
+		}
+		return shown
+	}
+	if got, want := helpKeys(), []string{"space", "←/→", "↑/↓", "home", "esc"}; !slices.Equal(got, want) {
+		t.Errorf("default help keys = %q, want %q", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "keymap.json")
+	if err := os.WriteFile(path, []byte(`{"SeekBackward": ["h"], "FasterPlayback": ["k", "+"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadKeyMap(path); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := helpKeys(), []string{"space", "h/→", "k/+/↓", "home", "esc"}; !slices.Equal(got, want) {
+		t.Errorf("remapped help keys = %q, want %q", got, want)
+	}
+}
+
+// enabledHelp lists the bindings help shows, by key.
+func enabledHelp(k help.KeyMap) map[string]string {
+	shown := make(map[string]string)
+	for _, group := range k.FullHelp() {
+		for _, binding := range group {
+			if binding.Enabled() {
+				shown[binding.Help().Key] = binding.Help().Desc
+			}
+		}
+	}
+	return shown
+}
+
+func TestHelpShowsOnlyActiveBindings(t *testing.T) {
+	theme.Init()
+	var m tea.Model = initMainModel(Session{Puzzle: newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "")}, Options{})
+	if desc, ok := enabledHelp(m.(mainModel).keys())["enter"]; ok {
+		t.Errorf("enter is shown to %s before the puzzle is solved", desc)
+	}
+	m = typeKeys(m, "c", "a", "t", "a", "r", "e", "t", "e", "n")
+	if !m.(mainModel).completion.shown {
+		t.Fatal("solving didn't open the summary")
+	}
+	if desc := enabledHelp(m.(mainModel).keys())["enter"]; desc != keys.ViewSummary.Help().Desc {
+		t.Errorf("enter is shown to %q once solved, want the summary", desc)
+	}
+
+	// Each view lists what its keys do there, though they do other things
+	// elsewhere.
+	for _, test := range []struct {
+		name string
+		help help.KeyMap
+		key  string
+		want string
+	}{
+		{"grid", keys, "ctrl+r", "go to referenced clue"},
+		{"clue list", clueListKeyMap(keys), "enter", "go to clue"},
+		{"library", libraryKeyMap(keys), "enter", "open puzzle"},
+		{"library", libraryKeyMap(keys), "ctrl+r", "reverse sort"},
+		{"library", libraryKeyMap(keys), "ctrl+a", "solve stats"},
+		{"completion", completionKeyMap(keys), "enter", "quit"},
+		{"editor", editorKeyMap(keys), "enter", "write clue"},
+		{"editor", editorKeyMap(keys), "ctrl+r", "resize grid"},
+		{"editor", editorKeyMap(keys), "ctrl+a", "autofill"},
+	} {
+		if got := enabledHelp(test.help)[test.key]; got != test.want {
+			t.Errorf("%s help shows %s to %q, want %q", test.name, test.key, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package solver
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/help"
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/sahilm/fuzzy"
+	"github.com/tylerwgrass/cruciterm/library"
+	"github.com/tylerwgrass/cruciterm/stats"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+var NUM_SHOWN_LIBRARY_ENTRIES int = 20
+
+type librarySort int
+
+const (
+	sortByDate librarySort = iota
+	sortByTitle
+	sortByAuthor
+	sortBySize
+	sortByStatus
+	numLibrarySorts
+)
+
+func (s librarySort) String() string {
+	return [...]string{"date", "title", "author", "size", "status"}[s]
+}
+
+// statusFilter limits the library to puzzles with one status. -1 shows all.
+type statusFilter int
+
+const showAllStatuses statusFilter = -1
+
+// libraryEntries implements fuzzy.Source over the title, author and file
+// name of every entry.
+type libraryEntries []library.Entry
+
+func (l libraryEntries) String(i int) string {
+	return l[i].Title + " " + l[i].Author + " " + filepath.Base(l[i].Path)
+}
+
+func (l libraryEntries) Len() int {
+	return len(l)
+}
+
+type libraryModel struct {
+	entries    libraryEntries
+	shown      []library.Entry
+	marked     map[string]bool
+	input      textinput.Model
+	help       help.Model
+	sortBy     librarySort
+	descending bool
+	filter     statusFilter
+	selected   int
+	offset     int
+	width      int
+	height     int
+	opened     []library.Entry
+	history    stats.Summary
+	showStats  bool
+}
+
+func initLibraryModel(entries []library.Entry, history stats.Summary) libraryModel {
+	input := textinput.New()
+	input.Prompt = "search: "
+	input.Placeholder = "title, author or file name"
+	input.Styles.Focused.Prompt = theme.Get().Foreground(theme.Primary())
+	input.Styles.Focused.Text = theme.Get()
+	input.Styles.Focused.Placeholder = theme.Get().Foreground(theme.Muted())
+	input.Focus()
+
+	help := help.New()
+	help.Styles.FullKey = theme.Get().Foreground(theme.Primary())
+	help.Styles.FullDesc = theme.Get().Foreground(theme.Secondary())
+	help.ShowAll = true
+
+	m := libraryModel{
+		entries:    entries,
+		marked:     make(map[string]bool),
+		input:      input,
+		help:       help,
+		sortBy:     sortByDate,
+		descending: true,
+		filter:     showAllStatuses,
+		history:    history,
+	}
+	m.updateShown()
+	return m
+}
+
+func (m libraryModel) Init() tea.Cmd {
+	return tea.Batch(tea.RequestBackgroundColor, textinput.Blink)
+}
+
+func (m libraryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.BackgroundColorMsg:
+		return m, tea.SetBackgroundColor(theme.Background())
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+	case tea.KeyMsg:
+		if m.showStats && !key.Matches(msg, keys.Quit) {
+			if key.Matches(msg, keys.Back, keys.ViewStats) {
+				m.showStats = false
+			}
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, keys.ViewStats):
+			m.showStats = true
+			return m, nil
+		case key.Matches(msg, keys.Back):
+			if m.input.Value() == "" {
+				return m, tea.Quit
+			}
+			m.input.Reset()
+			m.updateShown()
+			return m, nil
+		case key.Matches(msg, keys.Up):
+			m.moveSelection(-1)
+			return m, nil
+		case key.Matches(msg, keys.Down):
+			m.moveSelection(1)
+			return m, nil
+		case key.Matches(msg, keys.PageUp):
+			m.moveSelection(-NUM_SHOWN_LIBRARY_ENTRIES)
+			return m, nil
+		case key.Matches(msg, keys.PageDown):
+			m.moveSelection(NUM_SHOWN_LIBRARY_ENTRIES)
+			return m, nil
+		case key.Matches(msg, keys.SortLibrary):
+			m.sortBy = (m.sortBy + 1) % numLibrarySorts
+			m.descending = m.sortBy == sortByDate
+			m.updateShown()
+			return m, nil
+		case key.Matches(msg, keys.ReverseSort):
+			m.descending = !m.descending
+			m.updateShown()
+			return m, nil
+		case key.Matches(msg, keys.FilterStatus):
+			m.filter++
+			if m.filter > statusFilter(library.Solved) {
+				m.filter = showAllStatuses
+			}
+			m.updateShown()
+			return m, nil
+		case key.Matches(msg, keys.MarkPuzzle):
+			if len(m.shown) > 0 {
+				path := m.shown[m.selected].Path
+				m.marked[path] = !m.marked[path]
+				m.moveSelection(1)
+			}
+			return m, nil
+		case key.Matches(msg, keys.OpenPuzzle):
+			m.opened = m.selection()
+			if len(m.opened) == 0 {
+				return m, nil
+			}
+			return m, tea.Quit
+		}
+	}
+
+	previousQuery := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != previousQuery {
+		m.updateShown()
+	}
+	return m, cmd
+}
+
+// selection is every marked puzzle, in library order, or the highlighted
+// puzzle when none are marked.
+func (m libraryModel) selection() []library.Entry {
+	var selected []library.Entry
+	for _, entry := range m.entries {
+		if m.marked[entry.Path] {
+			selected = append(selected, entry)
+		}
+	}
+	if len(selected) == 0 && len(m.shown) > 0 {
+		selected = append(selected, m.shown[m.selected])
+	}
+	return selected
+}
+
+// updateShown filters and sorts the entries. While searching, entries are
+// ranked by how well they match instead.
+func (m *libraryModel) updateShown() {
+	m.selected, m.offset = 0, 0
+	m.shown = nil
+	query := strings.TrimSpace(m.input.Value())
+	if query != "" {
+		for _, match := range fuzzy.FindFrom(query, m.entries) {
+			if m.matchesFilter(m.entries[match.Index]) {
+				m.shown = append(m.shown, m.entries[match.Index])
+			}
+		}
+		return
+	}
+
+	for _, entry := range m.entries {
+		if m.matchesFilter(entry) {
+			m.shown = append(m.shown, entry)
+		}
+	}
+	slices.SortStableFunc(m.shown, func(a, b library.Entry) int {
+		var c int
+		switch m.sortBy {
+		case sortByDate:
+			c = a.Date.Compare(b.Date)
+		case sortByTitle:
+			c = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		case sortByAuthor:
+			c = strings.Compare(strings.ToLower(a.Author), strings.ToLower(b.Author))
+		case sortBySize:
+			c = cmp.Compare(a.NumRows*a.NumCols, b.NumRows*b.NumCols)
+		case sortByStatus:
+			c = cmp.Or(cmp.Compare(a.Status, b.Status), cmp.Compare(a.PercentFilled, b.PercentFilled))
+		}
+		if m.descending {
+			return -c
+		}
+		return c
+	})
+}
+
+func (m libraryModel) matchesFilter(entry library.Entry) bool {
+	return m.filter == showAllStatuses || library.Status(m.filter) == entry.Status
+}
+
+func (m *libraryModel) moveSelection(delta int) {
+	if len(m.shown) == 0 {
+		return
+	}
+	m.selected = min(max(m.selected+delta, 0), len(m.shown)-1)
+	if m.selected < m.offset {
+		m.offset = m.selected
+	} else if m.selected >= m.offset+NUM_SHOWN_LIBRARY_ENTRIES {
+		m.offset = m.selected - NUM_SHOWN_LIBRARY_ENTRIES + 1
+	}
+}
+
+func (m libraryModel) View() string {
+	TITLE_WIDTH := 36
+	AUTHOR_WIDTH := 22
+	headerStyle := theme.Get().Foreground(theme.Secondary()).Bold(true)
+	mutedStyle := theme.Get().Foreground(theme.Muted())
+	solvedStyle := theme.Get().Foreground(theme.Green())
+	progressStyle := theme.Get().Foreground(theme.Primary())
+
+	order := "ascending"
+	if m.descending {
+		order = "descending"
+	}
+	filter := "all puzzles"
+	if m.filter != showAllStatuses {
+		filter = library.Status(m.filter).String()
+	}
+	summary := mutedStyle.Render(fmt.Sprintf("%d of %d shown · %s · sorted by %s, %s",
+		len(m.shown), len(m.entries), filter, m.sortBy, order))
+
+	row := func(mark, title, author, date, size, status string) string {
+		return fmt.Sprintf("%-2s %-*s %-*s %-10s %-7s %s",
+			mark, TITLE_WIDTH, truncate(title, TITLE_WIDTH), AUTHOR_WIDTH, truncate(author, AUTHOR_WIDTH), date, size, status)
+	}
+
+	if m.showStats {
+		return m.statsView()
+	}
+
+	rows := []string{m.input.View(), summary, "", "  " + headerStyle.Render(row("", "TITLE", "AUTHOR", "DATE", "SIZE", "STATUS"))}
+	if len(m.entries) == 0 {
+		rows = append(rows, theme.Get().Foreground(theme.Red()).Render("no puzzles found; add directories to puzzleDirs in the config file"))
+	} else if len(m.shown) == 0 {
+		rows = append(rows, theme.Get().Foreground(theme.Red()).Render("no matching puzzles"))
+	}
+
+	end := min(m.offset+NUM_SHOWN_LIBRARY_ENTRIES, len(m.shown))
+	for i := m.offset; i < end; i++ {
+		entry := m.shown[i]
+		mark := ""
+		if m.marked[entry.Path] {
+			mark = "●"
+		}
+		title := entry.Title
+		if title == "" {
+			title = filepath.Base(entry.Path)
+		}
+		status := entry.Status.String()
+		statusStyle := mutedStyle
+		switch entry.Status {
+		case library.InProgress:
+			status = fmt.Sprintf("%s %d%%", status, entry.PercentFilled)
+			statusStyle = progressStyle
+		case library.Solved:
+			if entry.Elapsed > 0 {
+				status = fmt.Sprintf("%s in %s", status, entry.Elapsed.Round(time.Second))
+			}
+			statusStyle = solvedStyle
+		}
+		line := row(mark, title, entry.Author, entry.Date.Format(time.DateOnly),
+			fmt.Sprintf("%dx%d", entry.NumCols, entry.NumRows), "")
+		line = theme.Apply(line) + statusStyle.Render(status)
+		if i == m.selected {
+			line = theme.Get().Reverse(true).Render("⮕ ") + line
+		} else {
+			line = "  " + line
+		}
+		rows = append(rows, line)
+	}
+	rows = append(rows, "", m.help.View(libraryKeyMap(keys)))
+	return m.frame(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+func (m libraryModel) statsView() string {
+	hint := theme.Get().Foreground(theme.Muted()).Render(
+		fmt.Sprintf("%s or esc to go back", keys.ViewStats.Help().Key))
+	return m.frame(lipgloss.JoinVertical(lipgloss.Left, renderStats(m.history), "", hint))
+}
+
+func (m libraryModel) frame(content string) string {
+	content = theme.Get().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(theme.Primary()).
+		Padding(0, 2).
+		Render(content)
+	return theme.Get().Width(m.width).Height(m.height).Render(
+		lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content))
+}
+
+func truncate(s string, width int) string {
+	if len([]rune(s)) <= width {
+		return s
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
+
+// RunLibrary lets the user pick puzzles from the library, or look over their
+// solve history. It returns no entries if the user quit without opening any.
+// term is where to run it, or nil for the process's own terminal.
+func RunLibrary(entries []library.Entry, history stats.Summary, term *Terminal) ([]library.Entry, error) {
+	finalModel, err := runProgram(initLibraryModel(entries, history), term)
+	if err != nil {
+		return nil, err
+	}
+	return finalModel.(libraryModel).opened, nil
+}
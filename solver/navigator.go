@@ -18,6 +18,15 @@ type Cell struct {
 	prevDown        *puzzle.Clue
 	isAcrossClueEnd bool
 	isDownClueEnd   bool
+	// wrong marks a letter found to be incorrect by a check, until it is
+	// changed.
+	wrong    bool
+	revealed bool
+	// checked is set once a check or reveal has confirmed whether the letter
+	// is right, until it is changed.
+	checked bool
+	// corrected is set once a letter marked wrong has been changed.
+	corrected bool
 }
 
 type IterationMode int
@@ -44,6 +53,9 @@ type Navigator struct {
 	jumpLocation  JumpLocation
 	iterMode      IterationMode
 	halters       []IHalter
+	firstAcross   *puzzle.Clue
+	firstDown     *puzzle.Clue
+	prefs         prefs.Preferences
 }
 
 type NavigationDeltas struct {
@@ -61,15 +73,12 @@ type NavigationState struct {
 	haltedOnMatch bool
 }
 
-var firstAcrossClueCell *Cell
-var firstDownClueCell *Cell
-
 var defaultHalter = makeHalter(ValidSquare, false)
 
-func NewNavigator(puzzleGrid [][]string, puz *puzzle.PuzzleDefinition) *Navigator {
+func NewNavigator(puzzleGrid [][]string, puz *puzzle.PuzzleDefinition, preferences prefs.Preferences) *Navigator {
 	navGrid := make(NavigationGrid, len(puzzleGrid))
-	acrosses := puzzle.AcrossClues
-	downs := puzzle.DownClues
+	acrosses := puz.AcrossClues
+	downs := puz.DownClues
 	currentAcrossIndex := 0
 	currentDownIndex := 0
 	prevAcross := acrosses[len(acrosses)-1]
@@ -113,13 +122,6 @@ func NewNavigator(puzzleGrid [][]string, puz *puzzle.PuzzleDefinition) *Navigato
 					cell.prevDown = navGrid[row-1][col].prevDown
 				}
 			}
-			if cell.acrossClue == acrosses[0] {
-				firstAcrossClueCell = &cell
-			}
-			if cell.downClue == downs[0] {
-				firstDownClueCell = &cell
-			}
-
 			navGrid[row][col] = cell
 		}
 	}
@@ -132,6 +134,9 @@ func NewNavigator(puzzleGrid [][]string, puz *puzzle.PuzzleDefinition) *Navigato
 		jumpLocation:  ClueStart,
 		iterMode:      Clues,
 		halters:       []IHalter{defaultHalter},
+		firstAcross:   acrosses[0],
+		firstDown:     downs[0],
+		prefs:         preferences,
 	}
 }
 func (n *Navigator) resetNavigatorOptions() {
@@ -252,7 +257,7 @@ func (navigator Navigator) moveToNextValidCardinal(state *NavigationState) {
 	grid := *navigator.grid
 	for ok := true; ok; ok = !grid.isVisitable(state.row, state.col) {
 		nextRow, nextCol := state.row+deltas.dr, state.col+deltas.dc
-		if !prefs.GetBool(prefs.WrapOnArrowNavigation) {
+		if !navigator.prefs.GetBool(prefs.WrapOnArrowNavigation) {
 			if nextRow < 0 || nextRow > len(grid)-1 || nextCol < 0 || nextCol > len(grid[0])-1 {
 				state.row, state.col = startRow, startCol
 				return
@@ -323,9 +328,9 @@ func (navigator *Navigator) moveToNextClue(state *NavigationState) {
 			nextClue = currentClueCell.nextAcross
 			if nextClue.Num < currentClue.Num {
 				state.didWrap = true
-				if prefs.GetBool(prefs.SwapCursorOnGridWrap) {
+				if navigator.prefs.GetBool(prefs.SwapCursorOnGridWrap) {
 					navigator.orientation = Vertical
-					cell := grid[firstDownClueCell.downClue.StartRow][firstDownClueCell.downClue.StartCol]
+					cell := grid[navigator.firstDown.StartRow][navigator.firstDown.StartCol]
 					nextClue = cell.acrossClue
 				}
 			}
@@ -333,7 +338,7 @@ func (navigator *Navigator) moveToNextClue(state *NavigationState) {
 			nextClue = currentClueCell.prevAcross
 			if nextClue.Num > currentClue.Num {
 				state.didWrap = true
-				if prefs.GetBool(prefs.SwapCursorOnGridWrap) {
+				if navigator.prefs.GetBool(prefs.SwapCursorOnGridWrap) {
 					navigator.orientation = Vertical
 					cell := grid[currentClue.StartRow][currentClue.StartCol]
 					nextClue = cell.prevDown
@@ -346,9 +351,9 @@ func (navigator *Navigator) moveToNextClue(state *NavigationState) {
 			nextClue = currentClueCell.nextDown
 			if currentClue.Num > nextClue.Num {
 				state.didWrap = true
-				if prefs.GetBool(prefs.SwapCursorOnGridWrap) {
+				if navigator.prefs.GetBool(prefs.SwapCursorOnGridWrap) {
 					navigator.orientation = Horizontal
-					cell := grid[firstAcrossClueCell.acrossClue.StartRow][firstAcrossClueCell.acrossClue.StartCol]
+					cell := grid[navigator.firstAcross.StartRow][navigator.firstAcross.StartCol]
 					nextClue = cell.downClue
 				}
 			}
@@ -356,7 +361,7 @@ func (navigator *Navigator) moveToNextClue(state *NavigationState) {
 			nextClue = currentClueCell.prevDown
 			if currentClue.Num < nextClue.Num {
 				state.didWrap = true
-				if prefs.GetBool(prefs.SwapCursorOnGridWrap) {
+				if navigator.prefs.GetBool(prefs.SwapCursorOnGridWrap) {
 					navigator.orientation = Horizontal
 					cell := grid[currentClue.StartRow][currentClue.StartCol]
 					nextClue = cell.prevAcross
@@ -0,0 +1,66 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/v2/viewport"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+var NOTES_WIDTH int = 72
+var NOTES_HEIGHT int = 16
+
+type notesModel struct {
+	notes    string
+	viewport viewport.Model
+}
+
+func initNotesModel(puz *puzzle.PuzzleDefinition) notesModel {
+	vp := viewport.New(viewport.WithWidth(NOTES_WIDTH), viewport.WithHeight(NOTES_HEIGHT))
+	vp.SoftWrap = true
+	vp.Style = theme.Get()
+	vp.SetContent(puz.Notes)
+	m := notesModel{
+		notes:    puz.Notes,
+		viewport: vp,
+	}
+	m.setSize(NOTES_WIDTH+6, NOTES_HEIGHT+8)
+	return m
+}
+
+func (m notesModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m notesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m notesModel) hasNotes() bool {
+	return m.notes != ""
+}
+
+// setSize fits the notes viewport to its content, shrinking it when the
+// window is too small to show everything.
+func (m *notesModel) setSize(width, height int) {
+	viewportWidth := min(NOTES_WIDTH, max(width-6, 10))
+	contentHeight := lipgloss.Height(lipgloss.NewStyle().Width(viewportWidth).Render(m.notes))
+	m.viewport.SetWidth(viewportWidth)
+	m.viewport.SetHeight(min(NOTES_HEIGHT, contentHeight, max(height-8, 3)))
+}
+
+func (m notesModel) View() string {
+	containerStyle := theme.Get().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Primary()).
+		Padding(0, 2)
+	title := theme.Get().Foreground(theme.Primary()).Bold(true).Render("Notes")
+	footer := theme.Get().Foreground(theme.Muted()).Render(
+		fmt.Sprintf("%s to close • %3.f%%", keys.ViewNotes.Help().Key, m.viewport.ScrollPercent()*100))
+	return containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, title, "", m.viewport.View(), "", footer))
+}
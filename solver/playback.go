@@ -0,0 +1,192 @@
+package solver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/help"
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/progress"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/replay"
+	"github.com/tylerwgrass/cruciterm/stats"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+var PLAYBACK_SPEEDS = []float64{0.5, 1, 2, 4, 8, 16, 32}
+var PLAYBACK_TICK time.Duration = 50 * time.Millisecond
+var PLAYBACK_SEEK time.Duration = 5 * time.Second
+
+type playbackTickMsg struct{}
+
+// playbackModel re-renders a recorded solve over time.
+type playbackModel struct {
+	puzzle   puzzle.PuzzleDefinition
+	log      replay.Log
+	grid     gridModel
+	applied  int
+	position time.Duration
+	speed    int
+	paused   bool
+	progress progress.Model
+	help     help.Model
+	width    int
+	height   int
+}
+
+func initPlaybackModel(puz *puzzle.PuzzleDefinition, log replay.Log, speed float64) playbackModel {
+	help := help.New()
+	help.Styles.ShortKey = theme.Get().Foreground(theme.Primary())
+	help.Styles.ShortDesc = theme.Get().Foreground(theme.Secondary())
+	m := playbackModel{
+		puzzle:   *puz,
+		log:      log,
+		speed:    1,
+		progress: progress.New(progress.WithSolidFill(theme.Primary()), progress.WithoutPercentage()),
+		help:     help,
+	}
+	for i, s := range PLAYBACK_SPEEDS {
+		if s <= speed {
+			m.speed = i
+		}
+	}
+	m.seek(m.start())
+	return m
+}
+
+func (m playbackModel) Init() tea.Cmd {
+	return tea.Batch(tea.RequestBackgroundColor, m.tick())
+}
+
+func (m playbackModel) tick() tea.Cmd {
+	return tea.Tick(PLAYBACK_TICK, func(time.Time) tea.Msg { return playbackTickMsg{} })
+}
+
+func (m playbackModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.BackgroundColorMsg:
+		return m, tea.SetBackgroundColor(theme.Background())
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case playbackTickMsg:
+		if !m.paused {
+			m.seek(m.position + time.Duration(float64(PLAYBACK_TICK)*PLAYBACK_SPEEDS[m.speed]))
+			m.paused = m.position >= m.log.Duration()
+		}
+		return m, m.tick()
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Quit, keys.Back):
+			return m, tea.Quit
+		case key.Matches(msg, keys.PausePlayback):
+			if m.position >= m.log.Duration() {
+				m.seek(m.start())
+			}
+			m.paused = !m.paused
+		case key.Matches(msg, keys.SeekBackward):
+			m.seek(m.position - PLAYBACK_SEEK)
+		case key.Matches(msg, keys.SeekForward):
+			m.seek(m.position + PLAYBACK_SEEK)
+		case key.Matches(msg, keys.ListTop):
+			m.seek(m.start())
+		case key.Matches(msg, keys.ListBottom):
+			m.seek(m.log.Duration())
+		case key.Matches(msg, keys.FasterPlayback):
+			m.speed = min(m.speed+1, len(PLAYBACK_SPEEDS)-1)
+		case key.Matches(msg, keys.SlowerPlayback):
+			m.speed = max(m.speed-1, 0)
+		}
+	}
+	return m, nil
+}
+
+// start is when recording began, which is later than zero for solves that
+// were resumed before being recorded.
+func (m playbackModel) start() time.Duration {
+	if len(m.log.Events) == 0 {
+		return 0
+	}
+	return m.log.Events[0].At
+}
+
+// seek shows the grid as it was at position, replaying from the start when
+// going backwards.
+func (m *playbackModel) seek(position time.Duration) {
+	position = min(max(position, m.start()), m.log.Duration())
+	if position < m.position || m.applied == 0 {
+		puz := m.puzzle
+		puz.CurrentState = m.log.Initial
+		m.grid = initGridModel(&puz, nil)
+		m.applied = 0
+	}
+	m.position = position
+	grid := *m.grid.navigator.grid
+	for ; m.applied < len(m.log.Events) && m.log.Events[m.applied].At <= position; m.applied++ {
+		event := m.log.Events[m.applied]
+		if event.Row >= len(grid) || event.Col >= len(grid[event.Row]) {
+			continue
+		}
+		if event.Fill != "" {
+			grid[event.Row][event.Col].content = event.Fill
+			continue
+		}
+		m.grid.cursorY, m.grid.cursorX = event.Row, event.Col
+		m.grid.navOrientation = Horizontal
+		if event.Down {
+			m.grid.navOrientation = Vertical
+		}
+	}
+	m.grid.validateSolution()
+}
+
+func (m playbackModel) View() string {
+	title := m.puzzle.Title
+	if title == "" {
+		title = "Replay"
+	}
+	header := theme.Get().Foreground(theme.Primary()).Bold(true).Render("▶ " + title)
+
+	state := "playing"
+	if m.paused {
+		state = "paused"
+	}
+	duration := m.log.Duration() - m.start()
+	percent := 1.0
+	if duration > 0 {
+		percent = float64(m.position-m.start()) / float64(duration)
+	}
+	gridView := m.grid.View()
+	m.progress.SetWidth(max(lipgloss.Width(gridView), 30))
+	status := theme.Get().Foreground(theme.Muted()).Render(fmt.Sprintf("%s / %s · %gx · %s",
+		stats.FormatDuration(m.position), stats.FormatDuration(m.log.Duration()), PLAYBACK_SPEEDS[m.speed], state))
+
+	content := lipgloss.JoinVertical(lipgloss.Center,
+		header,
+		renderClueBar(m.grid, max(lipgloss.Width(gridView), 40)),
+		gridView,
+		"",
+		m.progress.ViewAs(percent),
+		status,
+		"",
+		m.help.View(playbackKeyMap(m.keys())),
+	)
+	return theme.Get().Width(m.width).Height(m.height).Render(
+		lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content))
+}
+
+// keys relabels the shared bindings for playback.
+func (m playbackModel) keys() keyMap {
+	k := keys
+	k.ListTop.SetHelp(k.ListTop.Help().Key, "restart")
+	k.Back.SetHelp(k.Back.Help().Key, "quit")
+	return k
+}
+
+// RunReplay plays back a recorded solve of puz, starting at the given speed.
+func RunReplay(puz *puzzle.PuzzleDefinition, log replay.Log, speed float64) error {
+	p := tea.NewProgram(initPlaybackModel(puz, log, speed), tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
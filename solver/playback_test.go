@@ -0,0 +1,49 @@
+package solver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/replay"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+func TestPlayback(t *testing.T) {
+	theme.Init()
+	puz := newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "")
+	m := initMainModel(Session{Puzzle: puz}, Options{})
+	// Type a letter a second, recording the grid after each.
+	r := newRecorder(replay.Log{}, m.grid, 0)
+	for i, k := range []string{"c", "a", "t", "space"} {
+		m = typeKeys(m, k).(mainModel)
+		r.observe(m.grid, time.Duration(i+1)*time.Second)
+	}
+	if r.log.Duration() != 4*time.Second {
+		t.Fatalf("recorded %v, want 4s", r.log.Duration())
+	}
+
+	p := initPlaybackModel(puz, r.log, 1)
+	for _, step := range []struct {
+		at       time.Duration
+		state    string
+		row, col int
+		down     bool
+	}{
+		{0, "---" + "---" + "---", 0, 0, false},
+		{2 * time.Second, "CA-" + "---" + "---", 0, 2, false},
+		{4 * time.Second, "CAT" + "---" + "---", 1, 0, true},
+		// Seeking back replays from the start.
+		{time.Second, "C--" + "---" + "---", 0, 1, false},
+		// Seeking past the end stops at the end.
+		{time.Minute, "CAT" + "---" + "---", 1, 0, true},
+	} {
+		p.seek(step.at)
+		if got := p.grid.state(); got != step.state {
+			t.Errorf("at %v grid = %q, want %q", step.at, got, step.state)
+		}
+		if p.grid.cursorY != step.row || p.grid.cursorX != step.col || (p.grid.navOrientation == Vertical) != step.down {
+			t.Errorf("at %v cursor at %d,%d (down %v), want %d,%d (down %v)", step.at,
+				p.grid.cursorY, p.grid.cursorX, p.grid.navOrientation == Vertical, step.row, step.col, step.down)
+		}
+	}
+}
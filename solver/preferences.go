@@ -8,25 +8,20 @@ import (
 	"github.com/tylerwgrass/cruciterm/theme"
 )
 
-var activePreferenceIndex int = 0
-
 type preferencesModel struct {
+	// prefs are the settings shown, changed in place when toggled.
+	prefs           prefs.Preferences
 	preferences     []prefs.SetPreference
 	preferencesList *list.List
+	activeIndex     int
 }
 
-func preferencesEnumerator(l list.Items, i int) string {
-	if i == activePreferenceIndex {
-		return "⮕ "
-	}
-	return ""
-}
-
-func initPreferencesModel() preferencesModel {
-	preferences := prefs.ListPreferences()
-	preferencesList := getPreferencesList(preferences)
+func initPreferencesModel(settings prefs.Preferences) preferencesModel {
+	preferences := settings.List()
+	preferencesList := getPreferencesList(preferences, 0)
 
 	return preferencesModel{
+		prefs:           settings,
 		preferences:     preferences,
 		preferencesList: preferencesList,
 	}
@@ -41,27 +36,32 @@ func (m preferencesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, keys.Up):
-			activePreferenceIndex = max(activePreferenceIndex-1, 0)
+			m.activeIndex = max(m.activeIndex-1, 0)
 		case key.Matches(msg, keys.Down):
-			activePreferenceIndex = min(activePreferenceIndex+1, len(m.preferences)-1)
+			m.activeIndex = min(m.activeIndex+1, len(m.preferences)-1)
 		case key.Matches(msg, keys.TogglePreference):
-			m.togglePreference(activePreferenceIndex)
+			m.togglePreference(m.activeIndex)
 		}
 	}
 
-	m.preferencesList = getPreferencesList(m.preferences)
+	m.preferencesList = getPreferencesList(m.preferences, m.activeIndex)
 	return m, nil
 }
 
 func (m *preferencesModel) togglePreference(activePreferenceIndex int) {
 	pref, val := m.preferences[activePreferenceIndex].Pref, m.preferences[activePreferenceIndex].Value.(bool)
 	m.preferences[activePreferenceIndex].Value = !val
-	prefs.SetBool(pref, !val)
+	m.prefs.SetBool(pref, !val)
 }
 
-func getPreferencesList(preferences []prefs.SetPreference) *list.List {
+func getPreferencesList(preferences []prefs.SetPreference, activeIndex int) *list.List {
 	preferencesList := list.New().
-		Enumerator(preferencesEnumerator)
+		Enumerator(func(_ list.Items, i int) string {
+			if i == activeIndex {
+				return "⮕ "
+			}
+			return ""
+		})
 
 	enabledIcon := theme.Get().Foreground(theme.Green()).Render(" ✓")
 	disabledIcon := theme.Get().Foreground(theme.Red()).Render(" x")
@@ -0,0 +1,115 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/tylerwgrass/cruciterm/coop"
+	"github.com/tylerwgrass/cruciterm/stats"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+var LEADERBOARD_NAME_WIDTH int = 14
+
+// raceSession reports a racer's progress to the host and keeps the
+// leaderboard it sends back.
+type raceSession struct {
+	coopConn
+	percent   int
+	checks    int
+	reveals   int
+	finished  bool
+	standings []coop.Standing
+}
+
+func newRaceSession(client *coop.Client) *raceSession {
+	return &raceSession{coopConn: coopConn{client: client}, percent: -1}
+}
+
+// observe reports changes in how far along the grid is, and the finished
+// grid once it is solved.
+func (s *raceSession) observe(grid gridModel) {
+	if s.finished {
+		return
+	}
+	if grid.solved {
+		s.finished = true
+		s.send(coop.Message{Type: coop.Finish, State: grid.state(), Checks: grid.checks, Reveals: grid.reveals})
+		return
+	}
+	percent := grid.percentFilled()
+	if percent != s.percent || grid.checks != s.checks || grid.reveals != s.reveals {
+		s.percent, s.checks, s.reveals = percent, grid.checks, grid.reveals
+		s.send(coop.Message{Type: coop.Progress, Percent: percent, Checks: grid.checks, Reveals: grid.reveals})
+	}
+}
+
+func (s *raceSession) apply(msg coopMsg) {
+	if coop.MessageType(msg.Type) == coop.Standings {
+		s.standings = msg.Standings
+	}
+}
+
+func (s *raceSession) status() string {
+	if s.disconnected {
+		return s.lostStatus()
+	}
+	return theme.Get().Foreground(theme.Muted()).Render("racing at " + s.client.Address)
+}
+
+// leaderboard shows every racer's progress, finishing place and time with
+// penalties. Times are each racer's own, from when they joined.
+func (s *raceSession) leaderboard() string {
+	headerStyle := theme.Get().Foreground(theme.Secondary()).Bold(true)
+	mutedStyle := theme.Get().Foreground(theme.Muted())
+	rows := []string{headerStyle.Render(fmt.Sprintf("   %-*s %-8s %7s %7s %7s",
+		LEADERBOARD_NAME_WIDTH, "RACER", "PROGRESS", "TIME", "PENALTY", "TOTAL"))}
+	for i, standing := range s.standings {
+		name := truncate(standing.Name, LEADERBOARD_NAME_WIDTH)
+		progress := fmt.Sprintf("%d%%", standing.Percent)
+		time, penalty, total := "", "", ""
+		if standing.Finished {
+			progress = ordinal(standing.Place)
+			time = stats.FormatDuration(standing.Elapsed)
+			total = stats.FormatDuration(standing.Total())
+		}
+		if standing.Penalty > 0 {
+			penalty = "+" + stats.FormatDuration(standing.Penalty)
+		}
+		line := fmt.Sprintf("%2d %-*s %-8s %7s %7s %7s",
+			i+1, LEADERBOARD_NAME_WIDTH, name, progress, time, penalty, total)
+		switch {
+		case standing.Player == s.client.Player:
+			line = theme.Get().Foreground(theme.Primary()).Render(line)
+		case !standing.Connected && !standing.Finished:
+			line = mutedStyle.Render(line + " (left)")
+		default:
+			line = theme.Apply(line)
+		}
+		rows = append(rows, line)
+	}
+	if len(s.standings) == 0 {
+		rows = append(rows, mutedStyle.Render("waiting for racers"))
+	}
+	rows = append(rows, mutedStyle.Render("each racer is timed from when they joined"))
+	return theme.Get().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(theme.Primary()).
+		Padding(0, 1).
+		Render(strings.Join(rows, "\n"))
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,51 @@
+package solver
+
+import (
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/replay"
+)
+
+// recorder adds every change to the grid and cursor to a replay log.
+type recorder struct {
+	log   replay.Log
+	state string
+	row   int
+	col   int
+	down  bool
+}
+
+// newRecorder continues log, or starts a new one from the grid as it is now
+// when log is empty.
+func newRecorder(log replay.Log, grid gridModel, at time.Duration) recorder {
+	r := recorder{log: log, state: grid.state(), row: -1, col: -1}
+	if len(r.log.Initial) != len(r.state) {
+		r.log = replay.Log{Initial: r.state}
+	}
+	r.observe(grid, at)
+	return r
+}
+
+func (r *recorder) observe(grid gridModel, at time.Duration) {
+	state := grid.state()
+	if state != r.state {
+		numCols := len((*grid.navigator.grid)[0])
+		for i := range state {
+			if state[i] != r.state[i] {
+				r.log.Events = append(r.log.Events, replay.Event{
+					At:   at,
+					Row:  i / numCols,
+					Col:  i % numCols,
+					Fill: string(state[i]),
+				})
+			}
+		}
+		r.state = state
+	}
+
+	down := grid.navOrientation == Vertical
+	if grid.cursorY != r.row || grid.cursorX != r.col || down != r.down {
+		r.row, r.col, r.down = grid.cursorY, grid.cursorX, down
+		r.log.Events = append(r.log.Events, replay.Event{At: at, Row: r.row, Col: r.col, Down: down})
+	}
+}
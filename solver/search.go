@@ -0,0 +1,211 @@
+package solver
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/sahilm/fuzzy"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+var NUM_SHOWN_SEARCH_RESULTS int = 12
+
+type searchEntry struct {
+	clue        *puzzle.Clue
+	orientation Orientation
+}
+
+type searchResult struct {
+	entry          searchEntry
+	matchedIndexes []int
+}
+
+// searchEntries implements fuzzy.Source over the text of every clue.
+type searchEntries []searchEntry
+
+func (s searchEntries) String(i int) string {
+	return s[i].clue.Clue
+}
+
+func (s searchEntries) Len() int {
+	return len(s)
+}
+
+type searchModel struct {
+	entries  searchEntries
+	results  []searchResult
+	grid     *NavigationGrid
+	input    textinput.Model
+	selected int
+	offset   int
+}
+
+func initSearchModel(puz *puzzle.PuzzleDefinition, grid *NavigationGrid) searchModel {
+	input := textinput.New()
+	input.Prompt = "search clues: "
+	input.Placeholder = "type part of a clue"
+	input.Styles.Focused.Prompt = theme.Get().Foreground(theme.Primary())
+	input.Styles.Focused.Text = theme.Get()
+	input.Styles.Focused.Placeholder = theme.Get().Foreground(theme.Muted())
+
+	entries := make(searchEntries, 0, len(puz.AcrossClues)+len(puz.DownClues))
+	for _, clue := range puz.AcrossClues {
+		entries = append(entries, searchEntry{clue: clue, orientation: Horizontal})
+	}
+	for _, clue := range puz.DownClues {
+		entries = append(entries, searchEntry{clue: clue, orientation: Vertical})
+	}
+
+	m := searchModel{
+		entries: entries,
+		grid:    grid,
+		input:   input,
+	}
+	m.updateResults()
+	return m
+}
+
+func (m searchModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *searchModel) open() tea.Cmd {
+	m.input.Reset()
+	m.updateResults()
+	return m.input.Focus()
+}
+
+func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Up):
+			m.moveSelection(-1)
+			return m, nil
+		case key.Matches(msg, keys.Down):
+			m.moveSelection(1)
+			return m, nil
+		case key.Matches(msg, keys.PageUp):
+			m.moveSelection(-NUM_SHOWN_SEARCH_RESULTS)
+			return m, nil
+		case key.Matches(msg, keys.PageDown):
+			m.moveSelection(NUM_SHOWN_SEARCH_RESULTS)
+			return m, nil
+		case key.Matches(msg, keys.SelectClue):
+			if len(m.results) == 0 {
+				return m, nil
+			}
+			entry := m.results[m.selected].entry
+			m.input.Blur()
+			return m, func() tea.Msg {
+				return clueSelectedMsg{clue: entry.clue, orientation: entry.orientation}
+			}
+		}
+	}
+
+	previousQuery := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != previousQuery {
+		m.updateResults()
+	}
+	return m, cmd
+}
+
+// updateResults ranks every clue against the current query. An empty query
+// lists all clues in puzzle order.
+func (m *searchModel) updateResults() {
+	m.selected, m.offset = 0, 0
+	query := strings.TrimSpace(m.input.Value())
+	if query == "" {
+		m.results = make([]searchResult, len(m.entries))
+		for i, entry := range m.entries {
+			m.results[i] = searchResult{entry: entry}
+		}
+		return
+	}
+
+	matches := fuzzy.FindFrom(query, m.entries)
+	m.results = make([]searchResult, len(matches))
+	for i, match := range matches {
+		m.results[i] = searchResult{
+			entry:          m.entries[match.Index],
+			matchedIndexes: match.MatchedIndexes,
+		}
+	}
+}
+
+func (m *searchModel) moveSelection(delta int) {
+	if len(m.results) == 0 {
+		return
+	}
+	m.selected = min(max(m.selected+delta, 0), len(m.results)-1)
+	if m.selected < m.offset {
+		m.offset = m.selected
+	} else if m.selected >= m.offset+NUM_SHOWN_SEARCH_RESULTS {
+		m.offset = m.selected - NUM_SHOWN_SEARCH_RESULTS + 1
+	}
+}
+
+func (m searchModel) View() string {
+	CONTAINER_WIDTH := 100
+	containerStyle := theme.Get().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(theme.Primary()).
+		Width(CONTAINER_WIDTH).
+		Padding(0, 2)
+	numberStyle := theme.Get().Foreground(theme.Secondary())
+	patternStyle := theme.Get().Foreground(theme.Muted())
+	matchStyle := theme.Get().Foreground(theme.Primary()).Bold(true)
+
+	rows := make([]string, 0, NUM_SHOWN_SEARCH_RESULTS+2)
+	rows = append(rows, m.input.View(), "")
+	if len(m.results) == 0 {
+		rows = append(rows, theme.Get().Foreground(theme.Red()).Render("no matching clues"))
+	}
+
+	end := min(m.offset+NUM_SHOWN_SEARCH_RESULTS, len(m.results))
+	for i := m.offset; i < end; i++ {
+		result := m.results[i]
+		clue := result.entry.clue
+		number := fmt.Sprintf("%4d%s", clue.Num, string(orientationName(result.entry.orientation)[0]))
+		pattern := m.grid.fillPattern(clue)
+
+		sb := theme.NewThemedStringBuilder(theme.Get())
+		for j, r := range clue.Clue {
+			if slices.Contains(result.matchedIndexes, j) {
+				sb.WriteStyledString(string(r), matchStyle)
+			} else {
+				sb.WriteString(string(r))
+			}
+		}
+
+		row := lipgloss.JoinHorizontal(lipgloss.Top,
+			numberStyle.Render(number),
+			"  ",
+			patternStyle.Width(max(puzzleSize(m.grid), len(pattern))+2).Render(pattern),
+			sb.String(),
+		)
+		if i == m.selected {
+			row = theme.Get().Reverse(true).Render("⮕ ") + row
+		} else {
+			row = "  " + row
+		}
+		rows = append(rows, row)
+	}
+
+	footer := theme.Get().Foreground(theme.Muted()).Render(
+		fmt.Sprintf("%d of %d clues", len(m.results), len(m.entries)))
+	rows = append(rows, "", footer)
+	return containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// puzzleSize is the length of the longest possible entry in grid.
+func puzzleSize(grid *NavigationGrid) int {
+	return max(len(*grid), len((*grid)[0]))
+}
@@ -2,15 +2,20 @@ package solver
 
 import (
 	"fmt"
-	"os"
+	"time"
 
 	"github.com/charmbracelet/bubbles/v2/help"
 	"github.com/charmbracelet/bubbles/v2/key"
 	"github.com/charmbracelet/bubbles/v2/stopwatch"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/tylerwgrass/cruciterm/coop"
+	prefs "github.com/tylerwgrass/cruciterm/preferences"
 	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/replay"
+	"github.com/tylerwgrass/cruciterm/stats"
 	"github.com/tylerwgrass/cruciterm/theme"
+	"github.com/tylerwgrass/cruciterm/web"
 )
 
 type mainModel struct {
@@ -20,11 +25,81 @@ type mainModel struct {
 	author      string
 	copyright   string
 	clues       cluesModel
+	gotoPrompt  gotoPromptModel
+	search      searchModel
+	notes       notesModel
 	grid        gridModel
 	preferences preferencesModel
+	completion  completionModel
+	recorder    recorder
+	coop        *coopSession
+	race        *raceSession
+	web         *webSession
 	stopwatch   stopwatch.Model
 	help        help.Model
 	activeView  ActiveView
+	options     Options
+	// elapsedBefore is time spent on the puzzle in earlier sessions.
+	elapsedBefore time.Duration
+	// startedSolved is set when the puzzle was already solved when opened.
+	startedSolved bool
+	tabbed        bool
+}
+
+// Options configure the solver.
+type Options struct {
+	// HideTimer keeps the timer running without displaying it.
+	HideTimer bool
+	// History is compared against on the completion screen. Its personal
+	// bests are updated as puzzles are solved.
+	History stats.Summary
+	// InLibrary is set when the solver was opened from the library.
+	InLibrary bool
+	// Terminal runs the solver somewhere other than the process's own
+	// terminal, e.g. an SSH session, when set.
+	Terminal *Terminal
+	// Preferences are the user's settings, shared by every tab. The
+	// defaults are used when nil.
+	Preferences prefs.Preferences
+	// ReadOnly keeps the solver from writing next to the puzzle files, e.g.
+	// when they are shared with other users. The share text can still be
+	// copied.
+	ReadOnly bool
+}
+
+// Session is a puzzle to solve in its own tab.
+type Session struct {
+	Puzzle *puzzle.PuzzleDefinition
+	// Path is the file the puzzle was loaded from.
+	Path string
+	// Elapsed is time already spent on the puzzle, e.g. when resuming.
+	Elapsed time.Duration
+	// Checks and Reveals count help already used on the puzzle, and
+	// Corrections and LettersTyped the editing done so far.
+	Checks       int
+	Reveals      int
+	Corrections  int
+	LettersTyped int
+	// Replay is continued when resuming a recorded solve.
+	Replay replay.Log
+	// Coop shares the puzzle with other players, or races them, when set.
+	Coop *coop.Client
+	// Web shows the solve in the browser when set.
+	Web *web.Server
+}
+
+// Result describes the puzzle as it was when the solver exited.
+type Result struct {
+	State   string
+	Elapsed time.Duration
+	Solved  bool
+	// NewlySolved is set when the puzzle was finished in this session.
+	NewlySolved  bool
+	Checks       int
+	Reveals      int
+	Corrections  int
+	LettersTyped int
+	Replay       replay.Log
 }
 
 type ActiveView int
@@ -32,37 +107,80 @@ type ActiveView int
 const (
 	GridAndClues ActiveView = iota
 	Preferences
+	ClueSearch
+	Notes
+	Completion
 )
 
-var solvingOrientation Orientation = Horizontal
-
-func initMainModel(puz *puzzle.PuzzleDefinition) mainModel {
-	grid := initGridModel(puz)
-	clues := initCluesModel(puz)
-	preferences := initPreferencesModel()
-	stopwatch := stopwatch.New()
+func initMainModel(session Session, options Options) mainModel {
+	puz := session.Puzzle
+	if options.Preferences == nil {
+		options.Preferences = prefs.Defaults()
+	}
+	grid := initGridModel(puz, options.Preferences)
+	grid.checks, grid.reveals = session.Checks, session.Reveals
+	grid.corrections, grid.lettersTyped = session.Corrections, session.LettersTyped
+	clues := initCluesModel(puz, grid.navigator.grid)
+	clues.trackCursor(grid)
+	preferences := initPreferencesModel(options.Preferences)
+	stopwatch := stopwatch.New(stopwatch.WithInterval(time.Second))
 	help := help.New()
 	help.Styles.FullKey = theme.Get().Foreground(theme.Primary())
 	help.Styles.FullDesc = theme.Get().Foreground(theme.Secondary())
 	help.ShowAll = true
-	return mainModel{
+	notes := initNotesModel(puz)
+	activeView := GridAndClues
+	if notes.hasNotes() && options.Preferences.GetBool(prefs.ShowNotesOnOpen) {
+		activeView = Notes
+	}
+	m := mainModel{
 		stopwatch:   stopwatch,
 		title:       puz.Title,
 		author:      puz.Author,
 		copyright:   puz.Copyright,
 		grid:        grid,
 		clues:       clues,
+		gotoPrompt:  initGotoPromptModel(puz),
+		search:      initSearchModel(puz, grid.navigator.grid),
 		help:        help,
-		activeView:  GridAndClues,
+		activeView:  activeView,
+		notes:       notes,
+		options:     options,
 		preferences: preferences,
+		completion:  initCompletionModel(puz, session.Path, options),
+		recorder:    newRecorder(session.Replay, grid, session.Elapsed),
+
+		elapsedBefore: session.Elapsed,
+		startedSolved: grid.solved,
 	}
+	if session.Coop != nil && session.Coop.Mode == coop.Race {
+		m.race = newRaceSession(session.Coop)
+		m.race.observe(m.grid)
+	} else if session.Coop != nil {
+		m.coop = newCoopSession(session.Coop, grid)
+		m.coop.follow(&m.grid)
+		m.clues.trackCursor(m.grid)
+		m.grid.peers = m.coop.cursors()
+		m.coop.observe(m.grid)
+	}
+	if session.Web != nil {
+		m.web = newWebSession(session.Web)
+		m.web.observe(m.grid, m.elapsed())
+	}
+	return m
 }
 
 func (m mainModel) Init() tea.Cmd {
-	return tea.Batch(
-		tea.RequestBackgroundColor,
-		m.stopwatch.Init(),
-	)
+	cmds := []tea.Cmd{tea.RequestBackgroundColor, m.stopwatch.Init()}
+	if m.coop != nil {
+		cmds = append(cmds, m.coop.wait())
+	} else if m.race != nil {
+		cmds = append(cmds, m.race.wait())
+	}
+	if m.web != nil {
+		cmds = append(cmds, m.web.wait())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -72,8 +190,44 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
+		m.notes.setSize(msg.Width, msg.Height)
 		return m, nil
 	case tea.KeyMsg:
+		if m.spectating() && m.activeView == GridAndClues {
+			return m.updateSpectator(msg)
+		}
+		if m.gotoPrompt.active && !key.Matches(msg, keys.Quit) {
+			gotoPrompt, cmd := m.gotoPrompt.Update(msg)
+			m.gotoPrompt = gotoPrompt.(gotoPromptModel)
+			return m, cmd
+		}
+		if m.activeView == ClueSearch && !key.Matches(msg, keys.Quit) {
+			if key.Matches(msg, keys.Back) {
+				m.activeView = GridAndClues
+				return m, nil
+			}
+			search, cmd := m.search.Update(msg)
+			m.search = search.(searchModel)
+			return m, cmd
+		}
+		if m.activeView == Notes && !key.Matches(msg, keys.Quit) {
+			if key.Matches(msg, keys.Back, keys.ViewNotes) {
+				m.activeView = GridAndClues
+				return m, nil
+			}
+			notes, cmd := m.notes.Update(msg)
+			m.notes = notes.(notesModel)
+			return m, cmd
+		}
+		if m.activeView == Completion && !key.Matches(msg, keys.Quit) {
+			if key.Matches(msg, keys.Back) {
+				m.activeView = GridAndClues
+				return m, nil
+			}
+			completion, cmd := m.completion.Update(msg)
+			m.completion = completion.(completionModel)
+			return m, cmd
+		}
 		switch {
 		case key.Matches(msg, keys.Quit):
 			return m, tea.Quit
@@ -83,7 +237,69 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				m.activeView = Preferences
 			}
+		case m.activeView == GridAndClues && key.Matches(msg, keys.FocusClues):
+			if m.clues.focused {
+				m.clues.focused = false
+			} else {
+				m.clues.focus()
+			}
+			return m, nil
+		case m.activeView == GridAndClues && key.Matches(msg, keys.GotoClue):
+			m.clues.focused = false
+			return m, m.gotoPrompt.open(m.grid.navOrientation)
+		case m.activeView == GridAndClues && key.Matches(msg, keys.SearchClues):
+			m.clues.focused = false
+			m.activeView = ClueSearch
+			return m, m.search.open()
+		case m.activeView == GridAndClues && !m.clues.focused && key.Matches(msg, m.keys().ViewSummary):
+			m.activeView = Completion
+			return m, nil
+		case m.activeView == GridAndClues && key.Matches(msg, keys.ViewNotes):
+			m.clues.focused = false
+			m.activeView = Notes
+			return m, nil
+		}
+	case clueSelectedMsg:
+		m.activeView = GridAndClues
+		m.grid.jumpToClue(msg.clue, msg.orientation)
+		m.gridChanged()
+		return m, nil
+	case coopMsg:
+		if m.race != nil {
+			m.race.apply(msg)
+			return m, m.race.wait()
 		}
+		m.coop.apply(&m.grid, msg)
+		if coop.MessageType(msg.Type) == coop.Solved {
+			// Everyone finishes with the host's time.
+			m.elapsedBefore = msg.Elapsed - m.stopwatch.Elapsed()
+			m.completion.elapsed = msg.Elapsed
+		}
+		m.clues.trackCursor(m.grid)
+		m.recorder.observe(m.grid, m.elapsed())
+		if m.web != nil {
+			m.web.observe(m.grid, m.elapsed())
+		}
+		m.openCompletion()
+		if m.grid.solved {
+			return m, tea.Batch(m.coop.wait(), m.stopwatch.Stop())
+		}
+		return m, m.coop.wait()
+	case webEditMsg:
+		m.web.apply(&m.grid, msg)
+		m.gridChanged()
+		m.openCompletion()
+		if m.grid.solved {
+			return m, tea.Batch(m.web.wait(), m.stopwatch.Stop())
+		}
+		return m, m.web.wait()
+	case coopClosedMsg:
+		if m.race != nil {
+			m.race.disconnected = true
+		} else {
+			m.coop.disconnected = true
+		}
+		return m, nil
 	}
 
 	if m.activeView == Preferences {
@@ -92,16 +308,85 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	grid, _ := m.grid.Update(msg)
-	m.grid = grid.(gridModel)
-	solvingOrientation = m.grid.navOrientation
+	var cluesCmd tea.Cmd
+	if _, isKey := msg.(tea.KeyMsg); isKey && m.clues.focused {
+		var clues tea.Model
+		clues, cluesCmd = m.clues.Update(msg)
+		m.clues = clues.(cluesModel)
+	} else {
+		grid, _ := m.grid.Update(msg)
+		m.grid = grid.(gridModel)
+		if _, isKey := msg.(tea.KeyMsg); isKey {
+			m.gridChanged()
+		} else {
+			m.clues.trackCursor(m.grid)
+		}
+	}
+	m.openCompletion()
 	var cmd tea.Cmd
 	if m.grid.solved {
 		cmd = m.stopwatch.Stop()
 	} else {
 		m.stopwatch, cmd = m.stopwatch.Update(msg)
 	}
-	return m, cmd
+	return m, tea.Batch(cmd, cluesCmd)
+}
+
+// keys leaves out going back to the summary until the puzzle has been
+// solved, as there is none to go back to.
+func (m mainModel) keys() keyMap {
+	k := keys
+	if !m.completion.shown {
+		k.ViewSummary.SetEnabled(false)
+	}
+	return k
+}
+
+func (m mainModel) spectating() bool {
+	return m.coop != nil && m.coop.spectating
+}
+
+// updateSpectator handles the few keys that do anything while watching, as
+// the grid can't be changed.
+func (m mainModel) updateSpectator(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, keys.FollowPlayer):
+		m.coop.followNext()
+		m.coop.follow(&m.grid)
+		m.grid.peers = m.coop.cursors()
+		m.clues.trackCursor(m.grid)
+	case key.Matches(msg, keys.ViewNotes):
+		m.activeView = Notes
+	case key.Matches(msg, m.keys().ViewSummary):
+		m.activeView = Completion
+	}
+	return m, nil
+}
+
+// gridChanged passes a local change to the grid on to the clue list, the
+// replay, any co-op players and the browser.
+func (m *mainModel) gridChanged() {
+	m.clues.trackCursor(m.grid)
+	m.recorder.observe(m.grid, m.elapsed())
+	if m.coop != nil {
+		m.coop.observe(m.grid)
+	} else if m.race != nil {
+		m.race.observe(m.grid)
+	}
+	if m.web != nil {
+		m.web.observe(m.grid, m.elapsed())
+	}
+}
+
+// openCompletion shows the completion screen the first time the puzzle is
+// solved.
+func (m *mainModel) openCompletion() {
+	if m.grid.solved && !m.startedSolved && !m.completion.shown {
+		m.completion.open(m.grid, m.elapsed())
+		m.activeView = Completion
+	}
 }
 
 func (m mainModel) View() string {
@@ -109,6 +394,16 @@ func (m mainModel) View() string {
 	var view string
 	if m.activeView == Preferences {
 		view = m.preferences.View()
+	} else if m.activeView == ClueSearch {
+		view = lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.search.View())
+	} else if m.activeView == Notes {
+		view = lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.notes.View())
+	} else if m.activeView == Completion {
+		completion := m.completion.View()
+		if m.race != nil {
+			completion = lipgloss.JoinVertical(lipgloss.Center, completion, m.race.leaderboard())
+		}
+		view = lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, completion)
 	} else {
 		view = m.getSolverView()
 	}
@@ -117,29 +412,105 @@ func (m mainModel) View() string {
 }
 
 func (m mainModel) getSolverView() string {
-	header := theme.Get().PaddingTop(m.height / 20).Render(fmt.Sprintf("%s\n%s %s", m.title, m.author, m.copyright))
+	title := m.title
+	if m.notes.hasNotes() {
+		title += theme.Get().Foreground(theme.Tertiary()).Render(
+			fmt.Sprintf("  ✎ notes (%s)", keys.ViewNotes.Help().Key))
+	}
+	header := theme.Get().PaddingTop(m.height / 20).Render(fmt.Sprintf("%s\n%s %s", title, m.author, m.copyright))
+	if m.coop != nil {
+		header += "\n" + m.coop.status()
+	} else if m.race != nil {
+		header += "\n" + m.race.status()
+	}
+	if m.web != nil {
+		header += "\n" + m.web.status()
+	}
 	if m.grid.solved {
-		header += theme.Apply("Solved!\n")
+		solved := "Solved!"
+		if m.completion.shown {
+			solved += fmt.Sprintf(" (%s for summary)", keys.ViewSummary.Help().Key)
+		}
+		header += theme.Apply(solved + "\n")
+	} else if m.grid.filledIncorrectly() {
+		header += theme.Get().Foreground(theme.Red()).Render(m.filledIncorrectlyMessage()) + "\n"
+	}
+	k := m.keys()
+	footer := m.help.View(k)
+	if m.tabbed {
+		footer = m.help.View(tabbedKeyMap(k))
+	}
+	if m.clues.focused {
+		footer = m.help.View(clueListKeyMap(k))
+	}
+	if m.spectating() {
+		footer = m.help.View(spectatorKeyMap(k))
+	}
+	if m.gotoPrompt.active {
+		footer = m.gotoPrompt.View()
 	}
-	footer := m.help.View(keys)
+	body := lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		lipgloss.JoinVertical(lipgloss.Left, m.grid.View(), m.timerView()),
+		m.clues.View(),
+	)
 	mainContent := lipgloss.JoinVertical(
 		lipgloss.Center,
 		header,
-		theme.Get().AlignVertical(lipgloss.Center).Render(
-			lipgloss.JoinHorizontal(
-				lipgloss.Top,
-				lipgloss.JoinVertical(lipgloss.Left, m.grid.View(), m.stopwatch.View()),
-				m.clues.View(),
-			)),
-		footer,
+		renderClueBar(m.grid, lipgloss.Width(body)),
+		theme.Get().AlignVertical(lipgloss.Center).Render(body),
 	)
+	if m.race != nil {
+		mainContent = lipgloss.JoinVertical(lipgloss.Center, mainContent, m.race.leaderboard())
+	}
+	mainContent = lipgloss.JoinVertical(lipgloss.Center, mainContent, footer)
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Top, mainContent)
 }
 
-func Run(puz *puzzle.PuzzleDefinition) {
-	p := tea.NewProgram(initMainModel(puz), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Alas, there's been an error: %v", err)
-		os.Exit(1)
+func (m mainModel) filledIncorrectlyMessage() string {
+	if m.grid.locked {
+		return "Every square is filled, but this puzzle's solution is locked so it can't be checked."
+	}
+	message := "Every square is filled, but something isn't right"
+	if m.grid.prefs.GetBool(prefs.ShowWrongSquareCount) {
+		count, _, _ := m.grid.wrongSquares()
+		message += fmt.Sprintf(": %s wrong", plural(count, "square"))
+	}
+	return message + fmt.Sprintf(". Press %s to jump to the first mistake, or %s to the first entry you haven't checked.",
+		keys.JumpToWrong.Help().Key, keys.JumpToUnchecked.Help().Key)
+}
+
+func (m mainModel) elapsed() time.Duration {
+	return m.elapsedBefore + m.stopwatch.Elapsed()
+}
+
+func (m mainModel) timerView() string {
+	if m.options.HideTimer {
+		return ""
+	}
+	return m.elapsed().String()
+}
+
+func (m mainModel) result() Result {
+	return Result{
+		State:        m.grid.state(),
+		Elapsed:      m.elapsed(),
+		Solved:       m.grid.solved,
+		NewlySolved:  m.grid.solved && !m.startedSolved,
+		Checks:       m.grid.checks,
+		Reveals:      m.grid.reveals,
+		Corrections:  m.grid.corrections,
+		LettersTyped: m.grid.lettersTyped,
+		Replay:       m.recorder.log,
+	}
+}
+
+// Run opens each session in a tab and returns how each puzzle was left, in
+// the same order, once the solver exits.
+func Run(sessions []Session, options Options) ([]Result, error) {
+	finalModel, err := runProgram(initTabsModel(sessions, options), options.Terminal)
+	if err != nil {
+		return nil, err
 	}
+	return finalModel.(tabsModel).results(), nil
 }
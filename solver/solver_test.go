@@ -0,0 +1,130 @@
+package solver
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	prefs "github.com/tylerwgrass/cruciterm/preferences"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+func newTestPuzzle(answer string, rows, cols int, clue string) *puzzle.PuzzleDefinition {
+	puz := &puzzle.PuzzleDefinition{
+		NumRows: rows,
+		NumCols: cols,
+		Answer:  answer,
+		CurrentState: strings.Map(func(r rune) rune {
+			if r == '.' {
+				return '.'
+			}
+			return '-'
+		}, answer),
+	}
+	clues := make([]string, len(answer)*2)
+	for i := range clues {
+		clues[i] = clue
+	}
+	puz.AssignClues(clues)
+	puz.NumClues = len(puz.AcrossClues) + len(puz.DownClues)
+	return puz
+}
+
+func typeKeys(m tea.Model, keys ...string) tea.Model {
+	for _, k := range keys {
+		var msg tea.KeyPressMsg
+		switch k {
+		case "tab":
+			msg = tea.KeyPressMsg{Code: tea.KeyTab}
+		case "space":
+			msg = tea.KeyPressMsg{Code: tea.KeySpace, Text: " "}
+		case "ctrl+p":
+			msg = tea.KeyPressMsg{Code: 'p', Mod: tea.ModCtrl}
+		default:
+			msg = tea.KeyPressMsg{Code: rune(k[0]), Text: k}
+		}
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func TestModelsAreIndependent(t *testing.T) {
+	theme.Init()
+	first := newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "first")
+	second := newTestPuzzle("AB.."+"CDEF"+"..GH", 3, 4, "second")
+
+	var a tea.Model = initMainModel(Session{Puzzle: first}, Options{})
+	var b tea.Model = initMainModel(Session{Puzzle: second}, Options{})
+
+	for _, m := range []struct {
+		name  string
+		model mainModel
+		puz   *puzzle.PuzzleDefinition
+	}{{"first", a.(mainModel), first}, {"second", b.(mainModel), second}} {
+		if len(m.model.clues.acrossClues) != len(m.puz.AcrossClues) || m.model.clues.acrossClues[0] != m.puz.AcrossClues[0] {
+			t.Errorf("%s model's across clues are not its puzzle's", m.name)
+		}
+		if len(m.model.clues.downClues) != len(m.puz.DownClues) || m.model.clues.downClues[0] != m.puz.DownClues[0] {
+			t.Errorf("%s model's down clues are not its puzzle's", m.name)
+		}
+		for _, clue := range append(m.model.clues.acrossClues, m.model.clues.downClues...) {
+			if clue.Clue != m.name {
+				t.Errorf("%s model has clue %v with text %q", m.name, clue, clue.Clue)
+			}
+		}
+	}
+	if a.(mainModel).grid.navigator.grid == b.(mainModel).grid.navigator.grid {
+		t.Fatal("models share a navigation grid")
+	}
+
+	// Solve both at once, which would race if they shared any state.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		a = typeKeys(a, "c", "a", "t")
+	}()
+	go func() {
+		defer wg.Done()
+		b = typeKeys(b, "x", "tab", "z")
+	}()
+	wg.Wait()
+
+	for _, m := range []struct {
+		name     string
+		model    mainModel
+		state    string
+		row, col int
+	}{
+		{"first", a.(mainModel), "CAT" + "---" + "---", 1, 0},
+		{"second", b.(mainModel), "X-.." + "Z---" + "..--", 1, 1},
+	} {
+		if got := m.model.grid.state(); got != m.state {
+			t.Errorf("%s grid = %q, want %q", m.name, got, m.state)
+		}
+		if m.model.grid.cursorY != m.row || m.model.grid.cursorX != m.col {
+			t.Errorf("%s cursor at %d,%d, want %d,%d", m.name, m.model.grid.cursorY, m.model.grid.cursorX, m.row, m.col)
+		}
+	}
+}
+
+func TestPreferencesArePerSolver(t *testing.T) {
+	theme.Init()
+	mine, theirs := prefs.Defaults(), prefs.Defaults()
+	var a tea.Model = initMainModel(Session{Puzzle: newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "")}, Options{Preferences: mine})
+	b := initMainModel(Session{Puzzle: newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "")}, Options{Preferences: theirs})
+
+	// JumpToEmptySquare is the first preference listed.
+	a = typeKeys(a, "ctrl+p", "space", "ctrl+p")
+	if a.(mainModel).grid.prefs.GetBool(prefs.JumpToEmptySquare) || mine.GetBool(prefs.JumpToEmptySquare) {
+		t.Error("toggling JumpToEmptySquare didn't turn it off")
+	}
+	if !b.grid.prefs.GetBool(prefs.JumpToEmptySquare) || !theirs.GetBool(prefs.JumpToEmptySquare) {
+		t.Error("toggling a preference in one solver changed another's")
+	}
+	if !prefs.Defaults().GetBool(prefs.JumpToEmptySquare) {
+		t.Error("toggling a preference changed the defaults")
+	}
+}
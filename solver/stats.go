@@ -0,0 +1,84 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/tylerwgrass/cruciterm/stats"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+var MAX_HISTOGRAM_BAR_WIDTH int = 30
+
+// renderStats shows averages, personal bests, streaks and a histogram of
+// solve times.
+func renderStats(summary stats.Summary) string {
+	headerStyle := theme.Get().Foreground(theme.Secondary()).Bold(true)
+	mutedStyle := theme.Get().Foreground(theme.Muted())
+	barStyle := theme.Get().Foreground(theme.Primary())
+	if summary.Total.Count == 0 {
+		return mutedStyle.Render("no solves recorded yet")
+	}
+
+	overview := []string{headerStyle.Render("OVERVIEW"),
+		fmt.Sprintf("%-16s %d (%d clean)", "solves", summary.Total.Count, summary.Clean),
+		fmt.Sprintf("%-16s %s", "average", stats.FormatDuration(summary.Total.Average)),
+		fmt.Sprintf("%-16s %d days", "current streak", summary.CurrentStreak),
+		fmt.Sprintf("%-16s %d days", "longest streak", summary.LongestStreak),
+	}
+	if summary.Best != nil {
+		overview = append(overview, fmt.Sprintf("%-16s %s, %s", "personal best",
+			stats.FormatDuration(summary.Best.Elapsed), truncate(summary.Best.Title, 30)))
+	}
+
+	weekdays := []string{headerStyle.Render("BY WEEKDAY")}
+	for day := time.Monday; ; day = (day + 1) % 7 {
+		average := summary.Weekday[day]
+		line := fmt.Sprintf("%-10s %3d", day, average.Count)
+		if average.Count > 0 {
+			line += "  " + stats.FormatDuration(average.Average)
+		}
+		weekdays = append(weekdays, line)
+		if day == time.Sunday {
+			break
+		}
+	}
+
+	sources := []string{headerStyle.Render(fmt.Sprintf("%-24s %5s %8s %8s", "BY SOURCE", "COUNT", "AVERAGE", "BEST"))}
+	for _, source := range summary.Sources() {
+		average := summary.Source[source]
+		best := "-"
+		if solve, ok := summary.BestBySource[source]; ok {
+			best = stats.FormatDuration(solve.Elapsed)
+		}
+		sources = append(sources, fmt.Sprintf("%-24s %5d %8s %8s",
+			truncate(source, 24), average.Count, stats.FormatDuration(average.Average), best))
+	}
+
+	largest := 0
+	for _, bucket := range summary.Histogram {
+		largest = max(largest, bucket.Count)
+	}
+	histogram := []string{headerStyle.Render("SOLVE TIMES")}
+	for _, bucket := range summary.Histogram {
+		bar := strings.Repeat("█", bucket.Count*MAX_HISTOGRAM_BAR_WIDTH/largest)
+		if bar == "" && bucket.Count > 0 {
+			bar = "▏"
+		}
+		histogram = append(histogram, fmt.Sprintf("%8s ", stats.BucketLabel(bucket))+
+			barStyle.Render(bar)+mutedStyle.Render(fmt.Sprintf(" %d", bucket.Count)))
+	}
+
+	column := theme.Get().PaddingRight(4)
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Top,
+			column.Render(lipgloss.JoinVertical(lipgloss.Left, overview...)),
+			lipgloss.JoinVertical(lipgloss.Left, weekdays...)),
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Top,
+			column.Render(lipgloss.JoinVertical(lipgloss.Left, sources...)),
+			lipgloss.JoinVertical(lipgloss.Left, histogram...)),
+	)
+}
@@ -0,0 +1,144 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	prefs "github.com/tylerwgrass/cruciterm/preferences"
+	"github.com/tylerwgrass/cruciterm/stats"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+var MAX_TAB_TITLE_WIDTH int = 24
+
+// tabsModel shows one mainModel per puzzle. Only the active tab receives
+// key presses and runs its timer.
+type tabsModel struct {
+	tabs   []mainModel
+	active int
+	width  int
+	height int
+}
+
+func initTabsModel(sessions []Session, options Options) tabsModel {
+	if options.Preferences == nil {
+		// Made here so that a change in one tab applies to them all.
+		options.Preferences = prefs.Defaults()
+	}
+	if options.History.BestBySource == nil {
+		// Shared by the tabs so that each compares against the others' solves.
+		options.History.BestBySource = make(map[string]stats.Solve)
+	}
+	tabs := make([]mainModel, len(sessions))
+	for i, session := range sessions {
+		tabs[i] = initMainModel(session, options)
+		tabs[i].tabbed = len(sessions) > 1
+	}
+	return tabsModel{tabs: tabs}
+}
+
+func (m tabsModel) Init() tea.Cmd {
+	return m.tabs[m.active].Init()
+}
+
+func (m tabsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		tabMsg := tea.WindowSizeMsg{Width: msg.Width, Height: msg.Height - lipgloss.Height(m.tabBar())}
+		cmds := make([]tea.Cmd, len(m.tabs))
+		for i := range m.tabs {
+			cmds[i] = m.updateTab(i, tabMsg)
+		}
+		return m, tea.Batch(cmds...)
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Quit):
+			return m, tea.Quit
+		case len(m.tabs) > 1 && key.Matches(msg, keys.NextTab):
+			return m, m.switchTo((m.active + 1) % len(m.tabs))
+		case len(m.tabs) > 1 && key.Matches(msg, keys.PrevTab):
+			return m, m.switchTo((m.active + len(m.tabs) - 1) % len(m.tabs))
+		}
+		return m, m.updateTab(m.active, msg)
+	case clueSelectedMsg:
+		return m, m.updateTab(m.active, msg)
+	}
+
+	// Timer ticks and the like are addressed to a single tab's components,
+	// which ignore messages meant for other tabs.
+	cmds := make([]tea.Cmd, len(m.tabs))
+	for i := range m.tabs {
+		cmds[i] = m.updateTab(i, msg)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *tabsModel) updateTab(i int, msg tea.Msg) tea.Cmd {
+	tab, cmd := m.tabs[i].Update(msg)
+	m.tabs[i] = tab.(mainModel)
+	return cmd
+}
+
+// switchTo makes tab i active, pausing the timer of the tab being left.
+func (m *tabsModel) switchTo(i int) tea.Cmd {
+	stop := m.tabs[m.active].stopwatch.Stop()
+	m.active = i
+	if m.tabs[i].grid.solved {
+		return stop
+	}
+	return tea.Batch(stop, m.tabs[i].stopwatch.Start())
+}
+
+func (m tabsModel) View() string {
+	bar := m.tabBar()
+	if bar == "" {
+		return m.tabs[m.active].View()
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, bar, m.tabs[m.active].View())
+}
+
+// tabBar lists every open puzzle with how much of it is filled in. It is
+// empty when only one puzzle is open.
+func (m tabsModel) tabBar() string {
+	if len(m.tabs) < 2 {
+		return ""
+	}
+	activeStyle := theme.Get().Foreground(theme.Primary()).Reverse(true).Padding(0, 1)
+	inactiveStyle := theme.Get().Foreground(theme.Secondary()).Padding(0, 1)
+
+	titleWidth := MAX_TAB_TITLE_WIDTH
+	if m.width > 0 {
+		titleWidth = max(min(titleWidth, m.width/len(m.tabs)-10), 4)
+	}
+	rendered := make([]string, len(m.tabs))
+	for i, tab := range m.tabs {
+		title := tab.title
+		if title == "" {
+			title = fmt.Sprintf("Puzzle %d", i+1)
+		}
+		if len([]rune(title)) > titleWidth {
+			title = string([]rune(title)[:titleWidth-1]) + "…"
+		}
+		status := fmt.Sprintf("%d%%", tab.grid.percentFilled())
+		if tab.grid.solved {
+			status = "✓"
+		}
+		style := inactiveStyle
+		if i == m.active {
+			style = activeStyle
+		}
+		rendered[i] = style.Render(fmt.Sprintf("%d %s %s", i+1, title, status))
+	}
+	return theme.Get().Width(m.width).Render(lipgloss.JoinHorizontal(lipgloss.Top, rendered...))
+}
+
+func (m tabsModel) results() []Result {
+	results := make([]Result, len(m.tabs))
+	for i, tab := range m.tabs {
+		results[i] = tab.result()
+	}
+	return results
+}
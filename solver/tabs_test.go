@@ -0,0 +1,63 @@
+package solver
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+func TestTabs(t *testing.T) {
+	theme.Init()
+	var m tea.Model = initTabsModel([]Session{
+		{Puzzle: newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "")},
+		{Puzzle: newTestPuzzle("AB"+"CD", 2, 2, "")},
+		{Puzzle: newTestPuzzle("XY"+"ZW", 2, 2, "")},
+	}, Options{})
+	nextTab := tea.KeyPressMsg{Code: ']', Mod: tea.ModAlt}
+	prevTab := tea.KeyPressMsg{Code: '[', Mod: tea.ModAlt}
+
+	m = typeKeys(m, "c")
+	m, _ = m.Update(nextTab)
+	m = typeKeys(m, "a", "b")
+	// Going back from the first tab wraps around to the last.
+	m, _ = m.Update(prevTab)
+	m, _ = m.Update(prevTab)
+	m = typeKeys(m, "x")
+
+	tabs := m.(tabsModel)
+	if tabs.active != 2 {
+		t.Errorf("tab %d is active, want 2", tabs.active)
+	}
+	for i, want := range []string{"C--" + "---" + "---", "AB" + "--", "X-" + "--"} {
+		if got := tabs.results()[i].State; got != want {
+			t.Errorf("tab %d grid = %q, want %q", i, got, want)
+		}
+	}
+	for _, tab := range tabs.tabs {
+		if !tab.tabbed {
+			t.Error("a tab doesn't know it is one of several")
+		}
+	}
+
+	bar := tabs.tabBar()
+	for _, want := range []string{"1 Puzzle 1 11%", "2 Puzzle 2 50%", "3 Puzzle 3 25%"} {
+		if !strings.Contains(bar, want) {
+			t.Errorf("tab bar %q doesn't show %q", bar, want)
+		}
+	}
+}
+
+func TestSingleTab(t *testing.T) {
+	theme.Init()
+	var m tea.Model = initTabsModel([]Session{{Puzzle: newTestPuzzle("AB"+"CD", 2, 2, "")}}, Options{})
+	m, _ = m.Update(tea.KeyPressMsg{Code: ']', Mod: tea.ModAlt})
+	tabs := m.(tabsModel)
+	if tabs.active != 0 || tabs.tabs[0].tabbed {
+		t.Error("a single puzzle is shown as a tab")
+	}
+	if bar := tabs.tabBar(); bar != "" {
+		t.Errorf("a single puzzle has a tab bar %q", bar)
+	}
+}
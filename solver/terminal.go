@@ -0,0 +1,130 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// Terminal is a terminal the solver runs in other than the process's own,
+// such as an SSH session. Several programs can run in it one after another,
+// e.g. the library and then the puzzles opened from it.
+type Terminal struct {
+	ctx     context.Context
+	output  io.Writer
+	environ []string
+	// input carries everything typed, so that only the running program
+	// reads it rather than one left blocked on the connection.
+	input chan []byte
+
+	mu      sync.Mutex
+	width   int
+	height  int
+	program *tea.Program
+}
+
+// NewTerminal runs programs on input and output until ctx is done. environ
+// is used to detect the terminal's capabilities, e.g. TERM and COLORTERM.
+func NewTerminal(ctx context.Context, input io.Reader, output io.Writer, environ []string, width, height int) *Terminal {
+	t := &Terminal{
+		ctx:     ctx,
+		output:  output,
+		environ: environ,
+		input:   make(chan []byte),
+		width:   width,
+		height:  height,
+	}
+	go t.read(input)
+	return t
+}
+
+func (t *Terminal) read(input io.Reader) {
+	defer close(t.input)
+	for {
+		buf := make([]byte, 256)
+		n, err := input.Read(buf)
+		if n > 0 {
+			select {
+			case t.input <- buf[:n]:
+			case <-t.ctx.Done():
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Resize tells the running program, and any started later, the terminal's
+// new size.
+func (t *Terminal) Resize(width, height int) {
+	t.mu.Lock()
+	t.width, t.height = width, height
+	p := t.program
+	t.mu.Unlock()
+	if p != nil {
+		p.Send(tea.WindowSizeMsg{Width: width, Height: height})
+	}
+}
+
+// run runs model in the terminal. A dropped connection ends the program as
+// if the user had quit.
+func (t *Terminal) run(model tea.Model) (tea.Model, error) {
+	r, w := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer w.Close()
+		for {
+			select {
+			case data, ok := <-t.input:
+				if !ok {
+					return
+				}
+				if _, err := w.Write(data); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	t.mu.Lock()
+	p := tea.NewProgram(model,
+		tea.WithAltScreen(),
+		tea.WithContext(t.ctx),
+		tea.WithInput(r),
+		tea.WithOutput(t.output),
+		tea.WithEnvironment(t.environ),
+		tea.WithWindowSize(t.width, t.height),
+		tea.WithoutSignalHandler(),
+	)
+	t.program = p
+	t.mu.Unlock()
+
+	finalModel, err := p.Run()
+
+	t.mu.Lock()
+	t.program = nil
+	t.mu.Unlock()
+	close(done)
+	r.Close()
+
+	if errors.Is(err, tea.ErrProgramKilled) && finalModel != nil {
+		err = nil
+	}
+	return finalModel, err
+}
+
+// runProgram runs model in term, or in the process's own terminal when term
+// is nil.
+func runProgram(model tea.Model, term *Terminal) (tea.Model, error) {
+	if term != nil {
+		return term.run(model)
+	}
+	return tea.NewProgram(model, tea.WithAltScreen()).Run()
+}
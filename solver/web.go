@@ -0,0 +1,92 @@
+package solver
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/tylerwgrass/cruciterm/theme"
+	"github.com/tylerwgrass/cruciterm/web"
+)
+
+type webEditMsg web.Edit
+
+// webSession shows the grid in the browser and takes edits made there.
+type webSession struct {
+	server *web.Server
+	last   web.Snapshot
+}
+
+func newWebSession(server *web.Server) *webSession {
+	return &webSession{server: server}
+}
+
+// wait delivers the next square filled in from the browser. There is
+// nothing to wait for when edits aren't allowed or once the server closes.
+func (s *webSession) wait() tea.Cmd {
+	if !s.server.AllowsEdits() {
+		return nil
+	}
+	return func() tea.Msg {
+		select {
+		case edit := <-s.server.Edits():
+			return webEditMsg(edit)
+		case <-s.server.Done():
+			return nil
+		}
+	}
+}
+
+// observe publishes grid to the browser if it has changed since the last
+// call.
+func (s *webSession) observe(grid gridModel, elapsed time.Duration) {
+	snapshot := web.Snapshot{
+		State:  grid.state(),
+		Row:    grid.cursorY,
+		Col:    grid.cursorX,
+		Down:   grid.navOrientation == Vertical,
+		Solved: grid.solved,
+	}
+	for i, row := range *grid.navigator.grid {
+		for j, cell := range row {
+			index := i*len(row) + j
+			if cell.wrong {
+				snapshot.Wrong = append(snapshot.Wrong, index)
+			}
+			if cell.revealed {
+				snapshot.Revealed = append(snapshot.Revealed, index)
+			}
+		}
+	}
+	if snapshot.State == s.last.State && snapshot.Row == s.last.Row && snapshot.Col == s.last.Col &&
+		snapshot.Down == s.last.Down && snapshot.Solved == s.last.Solved &&
+		slices.Equal(snapshot.Wrong, s.last.Wrong) && slices.Equal(snapshot.Revealed, s.last.Revealed) {
+		return
+	}
+	s.last = snapshot
+	snapshot.Elapsed = elapsed.Milliseconds()
+	s.server.Publish(snapshot)
+}
+
+// apply fills in a square from the browser.
+func (s *webSession) apply(grid *gridModel, edit webEditMsg) {
+	if grid.solved {
+		return
+	}
+	cells := *grid.navigator.grid
+	if edit.Row < 0 || edit.Row >= len(cells) || edit.Col < 0 || edit.Col >= len(cells[edit.Row]) || cells[edit.Row][edit.Col].content == "." {
+		return
+	}
+	// Changing a letter from the browser isn't the solver's correction.
+	grid.fillSquare(edit.Row, edit.Col, edit.Fill)
+	grid.validateSolution()
+}
+
+func (s *webSession) status() string {
+	viewers := s.server.Viewers()
+	if viewers == 0 {
+		return theme.Get().Foreground(theme.Muted()).Render("sharing at " + s.server.URL())
+	}
+	return theme.Apply(fmt.Sprintf("sharing at %s, %s watching", s.server.URL(), plural(viewers, "browser")))
+}
@@ -0,0 +1,88 @@
+package solver
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/tylerwgrass/cruciterm/theme"
+	"github.com/tylerwgrass/cruciterm/web"
+)
+
+func listenWeb(t *testing.T, allowEdits bool) *web.Server {
+	t.Helper()
+	server, err := web.Listen("127.0.0.1:0", newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, ""), allowEdits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// run runs cmd in the background, delivering what it returns.
+func run(cmd tea.Cmd) <-chan tea.Msg {
+	msgs := make(chan tea.Msg, 1)
+	go func() { msgs <- cmd() }()
+	return msgs
+}
+
+func TestWebEditsAreNotCorrections(t *testing.T) {
+	theme.Init()
+	server := listenWeb(t, true)
+	puz := newTestPuzzle("CAT"+"ARE"+"TEN", 3, 3, "")
+	var m tea.Model = initMainModel(Session{Puzzle: puz, Web: server}, Options{})
+	m = typeKeys(m, "x")
+
+	// Overwrite the solver's X from the browser.
+	msgs := run(m.(mainModel).web.wait())
+	req, err := http.NewRequest("POST", server.URL()+"/edit", strings.NewReader(`{"row": 0, "col": 0, "fill": "c"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Origin", server.URL())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	select {
+	case msg := <-msgs:
+		m, _ = m.Update(msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the browser's edit never arrived")
+	}
+
+	grid := m.(mainModel).grid
+	if got := grid.state(); got != "C--------" {
+		t.Errorf("state = %q, want %q", got, "C--------")
+	}
+	if grid.corrections != 0 {
+		t.Errorf("corrections = %d after a browser edit, want 0", grid.corrections)
+	}
+	grid.setContent(0, 0, "Q")
+	if grid.corrections != 1 {
+		t.Errorf("corrections = %d after the solver's own, want 1", grid.corrections)
+	}
+}
+
+func TestWebWait(t *testing.T) {
+	theme.Init()
+	if cmd := newWebSession(listenWeb(t, false)).wait(); cmd != nil {
+		t.Error("waiting for edits from a server that doesn't take any")
+	}
+
+	server := listenWeb(t, true)
+	msgs := run(newWebSession(server).wait())
+	server.Close()
+	select {
+	case msg := <-msgs:
+		if msg != nil {
+			t.Errorf("got %#v from a closed server", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("still waiting for edits after the server closed")
+	}
+}
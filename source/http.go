@@ -0,0 +1,139 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+)
+
+var ErrInvalidTemplate = errors.New("invalid URL template")
+
+// FETCH_TIMEOUT bounds each download.
+var FETCH_TIMEOUT time.Duration = 30 * time.Second
+
+// MAX_PUZZLE_SIZE is the most that is downloaded for one puzzle. Puzzle files
+// are a few kilobytes, so anything bigger is not a puzzle.
+var MAX_PUZZLE_SIZE int64 = 4 << 20
+
+// HTTP is a source that downloads puzzles from a URL that includes the date.
+type HTTP struct {
+	name     string
+	template string
+	format   string
+	// Days are the weekdays puzzles are published on. Every day when empty.
+	Days []time.Weekday
+	// Since is the first day a puzzle was published, if known.
+	Since  time.Time
+	Client *http.Client
+}
+
+// placeholders are replaced in URL templates by parts of the date.
+var placeholders = map[string]func(time.Time) string{
+	"{yyyy}": func(t time.Time) string { return t.Format("2006") },
+	"{yy}":   func(t time.Time) string { return t.Format("06") },
+	"{mm}":   func(t time.Time) string { return t.Format("01") },
+	"{m}":    func(t time.Time) string { return strconv.Itoa(int(t.Month())) },
+	"{mon}":  func(t time.Time) string { return strings.ToLower(t.Format("Jan")) },
+	"{dd}":   func(t time.Time) string { return t.Format("02") },
+	"{d}":    func(t time.Time) string { return strconv.Itoa(t.Day()) },
+}
+
+// NewHTTP makes a source named name that downloads each day's puzzle from
+// template, such as "https://example.com/puzzles/{yyyy}-{mm}-{dd}.puz". The
+// template can use {yyyy}, {yy}, {mm}, {m}, {mon}, {dd} and {d}. The
+// puzzle's format is taken from the template's extension.
+func NewHTTP(name, template string) (*HTTP, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	u, err := url.Parse(template)
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%w %q: must be an http or https URL", ErrInvalidTemplate, template)
+	}
+	if expand(template, time.Time{}) == template {
+		return nil, fmt.Errorf("%w %q: has no date placeholders", ErrInvalidTemplate, template)
+	}
+	format := strings.ToLower(path.Ext(u.Path))
+	if !slices.Contains(loader.Formats, format) {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidTemplate, template, loader.ErrFileNotSupported)
+	}
+	return &HTTP{name: name, template: template, format: format}, nil
+}
+
+func (h *HTTP) Name() string {
+	return h.name
+}
+
+func (h *HTTP) Format() string {
+	return h.format
+}
+
+// URL is where the puzzle for date is downloaded from.
+func (h *HTTP) URL(date time.Time) string {
+	return expand(h.template, date)
+}
+
+func expand(template string, date time.Time) string {
+	for placeholder, format := range placeholders {
+		template = strings.ReplaceAll(template, placeholder, format(date))
+	}
+	return template
+}
+
+func (h *HTTP) Dates(ctx context.Context, from, to time.Time) ([]time.Time, error) {
+	from, to = Day(from), Day(to)
+	if !h.Since.IsZero() && from.Before(Day(h.Since)) {
+		from = Day(h.Since)
+	}
+	var dates []time.Time
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if len(h.Days) == 0 || slices.Contains(h.Days, date.Weekday()) {
+			dates = append(dates, date)
+		}
+	}
+	return dates, nil
+}
+
+func (h *HTTP) Fetch(ctx context.Context, date time.Time) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, FETCH_TIMEOUT)
+	defer cancel()
+	u := h.URL(date)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "cruciterm")
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotPublished, u)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: %s", u, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MAX_PUZZLE_SIZE+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > MAX_PUZZLE_SIZE {
+		return nil, fmt.Errorf("%s: larger than %d bytes", u, MAX_PUZZLE_SIZE)
+	}
+	return data, nil
+}
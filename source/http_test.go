@@ -0,0 +1,170 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+func TestExpand(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{yyyy}-{mm}-{dd}", "2024-03-05"},
+		{"{yy}{mm}{dd}", "240305"},
+		{"{yyyy}/{yy}", "2024/24"},
+		{"{m}/{d}/{yy}", "3/5/24"},
+		{"{mon}{d}", "mar5"},
+		{"{mon}-{m}-{mm}", "mar-3-03"},
+		{"{d}{dd}", "505"},
+		{"{month}", "{month}"},
+	}
+	for _, test := range tests {
+		if got := expand(test.template, date); got != test.want {
+			t.Errorf("expand(%q) = %q, want %q", test.template, got, test.want)
+		}
+	}
+}
+
+func newTestHTTP(t *testing.T, template string) *HTTP {
+	t.Helper()
+	h, err := NewHTTP("test", template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestDates(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC) }
+	// March 4, 2024 is a Monday.
+	tests := []struct {
+		name  string
+		days  []time.Weekday
+		since time.Time
+		from  time.Time
+		to    time.Time
+		want  []time.Time
+	}{
+		{"every day", nil, time.Time{}, day(4), day(6), []time.Time{day(4), day(5), day(6)}},
+		{"one day", nil, time.Time{}, day(4), day(4), []time.Time{day(4)}},
+		{"backwards", nil, time.Time{}, day(6), day(4), nil},
+		{"times of day are ignored", nil, time.Time{}, day(4).Add(23 * time.Hour), day(5).Add(time.Hour), []time.Time{day(4), day(5)}},
+		{"weekdays", []time.Weekday{time.Monday, time.Wednesday, time.Friday}, time.Time{}, day(4), day(10), []time.Time{day(4), day(6), day(8)}},
+		{"no matching weekday", []time.Weekday{time.Sunday}, time.Time{}, day(4), day(9), nil},
+		{"since", nil, day(6).Add(time.Hour), day(4), day(7), []time.Time{day(6), day(7)}},
+		{"since after to", nil, day(10), day(4), day(7), nil},
+		{"since and weekdays", []time.Weekday{time.Monday}, day(5), day(1), day(18), []time.Time{day(11), day(18)}},
+	}
+	for _, test := range tests {
+		h := newTestHTTP(t, "https://example.com/{yyyy}-{mm}-{dd}.puz")
+		h.Days, h.Since = test.days, test.since
+		got, err := h.Dates(context.Background(), test.from, test.to)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !slices.EqualFunc(got, test.want, time.Time.Equal) {
+			t.Errorf("%s: Dates = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	maxSize := MAX_PUZZLE_SIZE
+	MAX_PUZZLE_SIZE = 16
+	t.Cleanup(func() { MAX_PUZZLE_SIZE = maxSize })
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.Header.Get("User-Agent") != "cruciterm" {
+			t.Errorf("requested with User-Agent %q", r.Header.Get("User-Agent"))
+		}
+		switch r.URL.Path {
+		case "/2024-03-01.puz":
+			w.Write([]byte("puzzle"))
+		case "/2024-03-02.puz":
+			w.Write([]byte(strings.Repeat("x", 16)))
+		case "/2024-03-03.puz":
+			w.Write([]byte(strings.Repeat("x", 17)))
+		case "/2024-03-04.puz":
+			http.Error(w, "down", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	h := newTestHTTP(t, server.URL+"/{yyyy}-{mm}-{dd}.puz")
+	h.Client = server.Client()
+
+	fetch := func(d int) ([]byte, error) {
+		return h.Fetch(context.Background(), time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC))
+	}
+	if data, err := fetch(1); err != nil || string(data) != "puzzle" {
+		t.Errorf("fetch = %q, %v, want the puzzle", data, err)
+	}
+	if data, err := fetch(2); err != nil || len(data) != 16 {
+		t.Errorf("fetching MAX_PUZZLE_SIZE bytes = %d bytes, %v", len(data), err)
+	}
+	if _, err := fetch(3); err == nil || !strings.Contains(err.Error(), "larger than 16 bytes") {
+		t.Errorf("fetching more than MAX_PUZZLE_SIZE: err = %v", err)
+	}
+	if _, err := fetch(4); err == nil || errors.Is(err, ErrNotPublished) || !strings.Contains(err.Error(), "500") {
+		t.Errorf("fetching from a failing server: err = %v", err)
+	}
+	if _, err := fetch(5); !errors.Is(err, ErrNotPublished) {
+		t.Errorf("fetching a missing puzzle: err = %v, want ErrNotPublished", err)
+	}
+	want := []string{"/2024-03-01.puz", "/2024-03-02.puz", "/2024-03-03.puz", "/2024-03-04.puz", "/2024-03-05.puz"}
+	if !slices.Equal(requested, want) {
+		t.Errorf("requested %q, want %q", requested, want)
+	}
+}
+
+func TestCacheRefusesBadPuz(t *testing.T) {
+	puz := &puzzle.PuzzleDefinition{
+		Title:        "Miscounted",
+		NumRows:      2,
+		NumCols:      2,
+		Answer:       "ABCD",
+		CurrentState: "----",
+	}
+	puz.AssignClues([]string{"1A", "3A", "1D", "2D"})
+	puz.NumClues = len(puz.AcrossClues) + len(puz.DownClues)
+	path := filepath.Join(t.TempDir(), "good.puz")
+	if err := loader.SaveFile(path, puz); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header claims one clue, though the grid has four entries.
+	data[0x2E] = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+	h := newTestHTTP(t, server.URL+"/{yyyy}-{mm}-{dd}.puz")
+	h.Client = server.Client()
+
+	cache := Cache{Dir: t.TempDir()}
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	if _, _, err := cache.Get(context.Background(), h, day); !errors.Is(err, loader.ErrFileParse) {
+		t.Errorf("getting a puzzle with a bad clue count: err = %v, want ErrFileParse", err)
+	}
+	if _, err := os.Stat(cache.Path(h, day)); !os.IsNotExist(err) {
+		t.Errorf("the bad puzzle was cached: %v", err)
+	}
+}
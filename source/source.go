@@ -0,0 +1,99 @@
+// Package source downloads puzzles from places that publish them by date,
+// such as a newspaper's daily crossword, and keeps them in a local cache that
+// the library can browse.
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+)
+
+var ErrNotPublished = errors.New("no puzzle was published that day")
+var ErrInvalidName = errors.New("source names may only use letters, digits, '-', '_' and '.'")
+
+// DateFormat is how dates are written in cached file names and on the
+// command line.
+const DateFormat = time.DateOnly
+
+// Source is somewhere puzzles are published, at most one a day.
+type Source interface {
+	// Name identifies the source. Its puzzles are cached under it.
+	Name() string
+	// Format is the extension of the puzzles the source publishes, one of
+	// loader.Formats.
+	Format() string
+	// Dates lists the days from from to to, inclusive, that should have a
+	// puzzle, oldest first.
+	Dates(ctx context.Context, from, to time.Time) ([]time.Time, error)
+	// Fetch downloads the puzzle published on date. It returns
+	// ErrNotPublished when there wasn't one.
+	Fetch(ctx context.Context, date time.Time) ([]byte, error)
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// Cache keeps downloaded puzzles in Dir, one directory per source, so that
+// each is only downloaded once.
+type Cache struct {
+	Dir string
+}
+
+// Path is where the puzzle src published on date is cached.
+func (c Cache) Path(src Source, date time.Time) string {
+	return filepath.Join(c.Dir, src.Name(), date.Format(DateFormat)+src.Format())
+}
+
+// Get returns the path to the puzzle src published on date, downloading it
+// first unless it is already cached. Downloads that loader can't read are not
+// cached.
+func (c Cache) Get(ctx context.Context, src Source, date time.Time) (path string, fetched bool, err error) {
+	if !validName.MatchString(src.Name()) {
+		return "", false, fmt.Errorf("%w: %q", ErrInvalidName, src.Name())
+	}
+	path = c.Path(src, date)
+	if _, err := os.Stat(path); err == nil {
+		return path, false, nil
+	}
+
+	data, err := src.Fetch(ctx, date)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := loader.Decode(data, src.Format()); err != nil {
+		return "", false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", false, err
+	}
+	// Write to a temporary file first so that an interrupted download never
+	// leaves half a puzzle in the cache.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return "", false, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", false, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", false, err
+	}
+	return path, true, nil
+}
+
+// Day truncates t to midnight, in t's location.
+func Day(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
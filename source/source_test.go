@@ -0,0 +1,102 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+// fakeSource publishes whatever is in puzzles, by date.
+type fakeSource struct {
+	name    string
+	puzzles map[string][]byte
+	fetches int
+}
+
+func (f *fakeSource) Name() string   { return f.name }
+func (f *fakeSource) Format() string { return ".json" }
+
+func (f *fakeSource) Dates(ctx context.Context, from, to time.Time) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (f *fakeSource) Fetch(ctx context.Context, date time.Time) ([]byte, error) {
+	f.fetches++
+	data, ok := f.puzzles[date.Format(DateFormat)]
+	if !ok {
+		return nil, ErrNotPublished
+	}
+	return data, nil
+}
+
+func testPuzzleJSON(t *testing.T) []byte {
+	t.Helper()
+	puz := &puzzle.PuzzleDefinition{
+		Title:        "Cached",
+		NumRows:      2,
+		NumCols:      2,
+		Answer:       "ABCD",
+		CurrentState: "----",
+	}
+	puz.AssignClues([]string{"1A", "3A", "1D", "2D"})
+	puz.NumClues = len(puz.AcrossClues) + len(puz.DownClues)
+	data, err := loader.EncodeJSON(puz, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestCacheGet(t *testing.T) {
+	cache := Cache{Dir: t.TempDir()}
+	src := &fakeSource{name: "daily", puzzles: map[string][]byte{
+		"2024-03-01": testPuzzleJSON(t),
+		"2024-03-02": []byte("<html>Subscribe to keep reading</html>"),
+	}}
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC) }
+
+	path, fetched, err := cache.Get(context.Background(), src, day(1))
+	if err != nil || !fetched {
+		t.Fatalf("Get = %q, %v, %v, want it fetched", path, fetched, err)
+	}
+	if want := filepath.Join(cache.Dir, "daily", "2024-03-01.json"); path != want {
+		t.Errorf("cached at %q, want %q", path, want)
+	}
+	if puz, err := loader.LoadFile(path); err != nil || puz.Title != "Cached" {
+		t.Errorf("cached puzzle loads as %q, %v", puz.Title, err)
+	}
+	if _, fetched, err := cache.Get(context.Background(), src, day(1)); err != nil || fetched || src.fetches != 1 {
+		t.Errorf("getting it again: fetched %v, %v, %d fetches, want it from the cache", fetched, err, src.fetches)
+	}
+
+	// Something that isn't a puzzle is refused rather than cached.
+	if _, _, err := cache.Get(context.Background(), src, day(2)); err == nil {
+		t.Error("cached a download that isn't a puzzle")
+	}
+	if _, _, err := cache.Get(context.Background(), src, day(3)); !errors.Is(err, ErrNotPublished) {
+		t.Errorf("getting a day with no puzzle: err = %v, want ErrNotPublished", err)
+	}
+	files, err := os.ReadDir(filepath.Join(cache.Dir, "daily"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	if got := strings.Join(names, " "); got != "2024-03-01.json" {
+		t.Errorf("cache holds %q, want only the puzzle", got)
+	}
+
+	src.name = "../escape"
+	if _, _, err := cache.Get(context.Background(), src, day(1)); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("getting from a source named %q: err = %v, want ErrInvalidName", src.name, err)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/stats"
+)
+
+func runStats(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	asJSON := fs.Bool("json", false, "print every recorded solve as JSON")
+	if code, ok := parseFlags(fs, args, 0); !ok {
+		return code
+	}
+
+	_, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+
+	solves, err := stats.DefaultStore().Load()
+	if err != nil {
+		return fail(cmd, err)
+	}
+	if *asJSON {
+		data, err := json.MarshalIndent(solves, "", "  ")
+		if err != nil {
+			return fail(cmd, err)
+		}
+		fmt.Println(string(data))
+		return exitOK
+	}
+	if len(solves) == 0 {
+		fmt.Println("No solves recorded yet.")
+		return exitOK
+	}
+
+	summary := stats.Summarize(solves, time.Now())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Solves:\t%d (%d clean)\n", summary.Total.Count, summary.Clean)
+	fmt.Fprintf(w, "Average:\t%s\n", stats.FormatDuration(summary.Total.Average))
+	if summary.Best != nil {
+		fmt.Fprintf(w, "Personal best:\t%s, %s\n", stats.FormatDuration(summary.Best.Elapsed), summary.Best.Title)
+	}
+	fmt.Fprintf(w, "Current streak:\t%d days\n", summary.CurrentStreak)
+	fmt.Fprintf(w, "Longest streak:\t%d days\n", summary.LongestStreak)
+	w.Flush()
+
+	fmt.Println("\nBY WEEKDAY")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DAY\tCOUNT\tAVERAGE")
+	for i := range 7 {
+		day := (time.Monday + time.Weekday(i)) % 7
+		average := summary.Weekday[day]
+		if average.Count > 0 {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", day, average.Count, stats.FormatDuration(average.Average))
+		}
+	}
+	w.Flush()
+
+	fmt.Println("\nBY SOURCE")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tCOUNT\tAVERAGE\tBEST")
+	for _, source := range summary.Sources() {
+		best := "-"
+		if solve, ok := summary.BestBySource[source]; ok {
+			best = stats.FormatDuration(solve.Elapsed)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", source, summary.Source[source].Count, stats.FormatDuration(summary.Source[source].Average), best)
+	}
+	w.Flush()
+
+	fmt.Println("\nSOLVE TIMES")
+	for _, bucket := range summary.Histogram {
+		fmt.Printf("%8s %s %d\n", stats.BucketLabel(bucket), bar(bucket.Count, summary.Histogram), bucket.Count)
+	}
+	return exitOK
+}
+
+func bar(count int, histogram []stats.Bucket) string {
+	largest := 0
+	for _, bucket := range histogram {
+		largest = max(largest, bucket.Count)
+	}
+	width := count * 40 / largest
+	if width == 0 && count > 0 {
+		return "▏"
+	}
+	return strings.Repeat("█", width)
+}
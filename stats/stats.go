@@ -0,0 +1,260 @@
+// Package stats keeps a history of completed solves and summarizes it.
+package stats
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/config"
+	"github.com/tylerwgrass/cruciterm/logger"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+// Solve is one completed puzzle.
+type Solve struct {
+	Hash    string        `json:"hash"`
+	Title   string        `json:"title"`
+	Author  string        `json:"author"`
+	Source  string        `json:"source"`
+	Date    time.Time     `json:"date"`
+	Elapsed time.Duration `json:"elapsed"`
+	Checks  int           `json:"checks"`
+	Reveals int           `json:"reveals"`
+	// Clean solves used no checks or reveals.
+	Clean bool `json:"clean"`
+}
+
+func NewSolve(puz *puzzle.PuzzleDefinition, elapsed time.Duration, checks, reveals int) Solve {
+	return Solve{
+		Hash:    puz.Hash(),
+		Title:   puz.Title,
+		Author:  puz.Author,
+		Source:  Source(puz),
+		Date:    time.Now(),
+		Elapsed: elapsed,
+		Checks:  checks,
+		Reveals: reveals,
+		Clean:   checks == 0 && reveals == 0,
+	}
+}
+
+var copyrightNoise = regexp.MustCompile(`(?i)©|\(c\)|copyright|all rights reserved|\b\d{4}\b|[,.;]`)
+
+// Source names the publisher of a puzzle, taken from its copyright line, or
+// its author when there is none.
+func Source(puz *puzzle.PuzzleDefinition) string {
+	source := strings.Join(strings.Fields(copyrightNoise.ReplaceAllString(puz.Copyright, " ")), " ")
+	if source == "" {
+		source = strings.TrimSpace(puz.Author)
+	}
+	if source == "" {
+		return "unknown"
+	}
+	return source
+}
+
+// Store appends solves to a JSON lines file.
+type Store struct {
+	Path string
+}
+
+func DefaultStore() Store {
+	return Store{Path: filepath.Join(config.StateDir(), "stats.jsonl")}
+}
+
+func (s Store) Record(solve Solve) error {
+	data, err := json.Marshal(solve)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads every recorded solve, oldest first. Lines that cannot be parsed
+// are skipped.
+func (s Store) Load() ([]Solve, error) {
+	f, err := os.Open(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var solves []Solve
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var solve Solve
+		if err := json.Unmarshal(scanner.Bytes(), &solve); err != nil {
+			logger.Warn("skipping unreadable solve", "path", s.Path, "line", line, "err", err)
+			continue
+		}
+		solves = append(solves, solve)
+	}
+	return solves, scanner.Err()
+}
+
+// FormatDuration shows a solve time as m:ss, or h:mm:ss past an hour.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// Average is the mean solve time of a group of solves.
+type Average struct {
+	Count   int
+	Average time.Duration
+}
+
+func (a *Average) add(elapsed time.Duration) {
+	a.Average = (a.Average*time.Duration(a.Count) + elapsed) / time.Duration(a.Count+1)
+	a.Count++
+}
+
+// Bucket counts the solves that took at least Min and less than Max.
+type Bucket struct {
+	Min   time.Duration
+	Max   time.Duration
+	Count int
+}
+
+// BucketLabel describes a bucket's range in minutes, e.g. "6-9m".
+func BucketLabel(b Bucket) string {
+	return fmt.Sprintf("%d-%dm", int(b.Min.Minutes()), int(b.Max.Minutes()))
+}
+
+var NUM_HISTOGRAM_BUCKETS int = 10
+
+// Summary describes a solve history.
+type Summary struct {
+	Total   Average
+	Clean   int
+	Weekday [7]Average
+	Source  map[string]Average
+	// Best and BestBySource only consider clean solves.
+	Best          *Solve
+	BestBySource  map[string]Solve
+	CurrentStreak int
+	LongestStreak int
+	Histogram     []Bucket
+}
+
+// Sources lists the sources in the summary alphabetically.
+func (s Summary) Sources() []string {
+	sources := make([]string, 0, len(s.Source))
+	for source := range s.Source {
+		sources = append(sources, source)
+	}
+	slices.SortFunc(sources, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return sources
+}
+
+// Summarize computes averages, bests, streaks and a histogram of solve times.
+// Streaks count consecutive days, ending today or yesterday relative to now,
+// with at least one solve.
+func Summarize(solves []Solve, now time.Time) Summary {
+	summary := Summary{
+		Source:       make(map[string]Average),
+		BestBySource: make(map[string]Solve),
+	}
+	for _, solve := range solves {
+		summary.Total.add(solve.Elapsed)
+		summary.Weekday[solve.Date.Weekday()].add(solve.Elapsed)
+		source := summary.Source[solve.Source]
+		source.add(solve.Elapsed)
+		summary.Source[solve.Source] = source
+		if !solve.Clean {
+			continue
+		}
+		summary.Clean++
+		if summary.Best == nil || solve.Elapsed < summary.Best.Elapsed {
+			summary.Best = &solve
+		}
+		if best, ok := summary.BestBySource[solve.Source]; !ok || solve.Elapsed < best.Elapsed {
+			summary.BestBySource[solve.Source] = solve
+		}
+	}
+	summary.CurrentStreak, summary.LongestStreak = streaks(solves, now)
+	summary.Histogram = histogram(solves)
+	return summary
+}
+
+func day(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func streaks(solves []Solve, now time.Time) (current, longest int) {
+	days := make([]time.Time, len(solves))
+	for i, solve := range solves {
+		days[i] = day(solve.Date)
+	}
+	slices.SortFunc(days, func(a, b time.Time) int { return a.Compare(b) })
+	days = slices.CompactFunc(days, time.Time.Equal)
+
+	run := 0
+	for i, d := range days {
+		if i > 0 && days[i-1].AddDate(0, 0, 1).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+	if len(days) > 0 {
+		last, today := days[len(days)-1], day(now)
+		if last.Equal(today) || last.AddDate(0, 0, 1).Equal(today) {
+			current = run
+		}
+	}
+	return current, longest
+}
+
+// histogram splits solve times into at most NUM_HISTOGRAM_BUCKETS buckets a
+// whole number of minutes wide.
+func histogram(solves []Solve) []Bucket {
+	if len(solves) == 0 {
+		return nil
+	}
+	slowest := slices.MaxFunc(solves, func(a, b Solve) int { return cmp.Compare(a.Elapsed, b.Elapsed) }).Elapsed
+	minutes := int(slowest/time.Minute)/NUM_HISTOGRAM_BUCKETS + 1
+	width := time.Duration(minutes) * time.Minute
+	buckets := make([]Bucket, int(slowest/width)+1)
+	for i := range buckets {
+		buckets[i].Min = time.Duration(i) * width
+		buckets[i].Max = time.Duration(i+1) * width
+	}
+	for _, solve := range solves {
+		buckets[solve.Elapsed/width].Count++
+	}
+	return buckets
+}
@@ -0,0 +1,160 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+)
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2024, time.March, 6, 20, 0, 0, 0, time.Local)
+	daysAgo := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+	solves := []Solve{
+		{Source: "NYT", Date: daysAgo(0), Elapsed: 7 * time.Minute, Clean: true},
+		{Source: "NYT", Date: daysAgo(1), Elapsed: 13 * time.Minute, Clean: true},
+		{Source: "LAT", Date: daysAgo(3), Elapsed: 4 * time.Minute, Checks: 2},
+		{Source: "LAT", Date: daysAgo(3), Elapsed: 10 * time.Minute, Clean: true},
+	}
+	summary := Summarize(solves, now)
+
+	if summary.Total.Count != 4 || summary.Total.Average != 8*time.Minute+30*time.Second {
+		t.Errorf("total is %+v", summary.Total)
+	}
+	if summary.Clean != 3 {
+		t.Errorf("%d clean solves, want 3", summary.Clean)
+	}
+	if nyt := summary.Source["NYT"]; nyt.Count != 2 || nyt.Average != 10*time.Minute {
+		t.Errorf("NYT average is %+v", nyt)
+	}
+	if got := summary.Sources(); !slices.Equal(got, []string{"LAT", "NYT"}) {
+		t.Errorf("sources are %q", got)
+	}
+	// Only clean solves count as bests.
+	if summary.Best == nil || summary.Best.Elapsed != 7*time.Minute {
+		t.Errorf("best is %+v, want the 7 minute solve", summary.Best)
+	}
+	if best := summary.BestBySource["LAT"]; best.Elapsed != 10*time.Minute {
+		t.Errorf("LAT best is %v, want the clean 10 minutes", best.Elapsed)
+	}
+	if wednesday := summary.Weekday[time.Wednesday]; wednesday.Count != 1 {
+		t.Errorf("%d solves on Wednesday, want 1", wednesday.Count)
+	}
+
+	// Minutes 0-1, 2-3, and so on up to the slowest, 13 minutes.
+	if len(summary.Histogram) != 7 || summary.Histogram[0].Max != 2*time.Minute {
+		t.Fatalf("histogram is %+v", summary.Histogram)
+	}
+	var counts []int
+	for _, bucket := range summary.Histogram {
+		counts = append(counts, bucket.Count)
+	}
+	if !slices.Equal(counts, []int{0, 0, 1, 1, 0, 1, 1}) {
+		t.Errorf("histogram counts are %v", counts)
+	}
+	if got := BucketLabel(summary.Histogram[3]); got != "6-8m" {
+		t.Errorf("fourth bucket is labelled %q", got)
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	now := time.Date(2024, time.March, 6, 8, 0, 0, 0, time.Local)
+	on := func(days ...int) []Solve {
+		var solves []Solve
+		for _, n := range days {
+			solves = append(solves, Solve{Date: now.AddDate(0, 0, -n), Elapsed: time.Minute})
+		}
+		return solves
+	}
+	tests := []struct {
+		name    string
+		solves  []Solve
+		current int
+		longest int
+	}{
+		{"none", nil, 0, 0},
+		{"today", on(0), 1, 1},
+		{"twice in a day", on(0, 0), 1, 1},
+		{"ending yesterday", on(1, 2, 3), 3, 3},
+		{"broken", on(2, 3), 0, 2},
+		{"longest earlier", on(0, 5, 6, 7, 8), 1, 4},
+		{"out of order", on(1, 0, 2), 3, 3},
+	}
+	for _, test := range tests {
+		summary := Summarize(test.solves, now)
+		if summary.CurrentStreak != test.current || summary.LongestStreak != test.longest {
+			t.Errorf("%s: streaks %d and %d, want %d and %d",
+				test.name, summary.CurrentStreak, summary.LongestStreak, test.current, test.longest)
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		copyright string
+		author    string
+		want      string
+	}{
+		{"© 2024 The New York Times", "Someone", "The New York Times"},
+		{"Copyright (c) 2023, Los Angeles Times. All rights reserved.", "", "Los Angeles Times"},
+		{"", " Jane Doe ", "Jane Doe"},
+		{"© 2024", "", "unknown"},
+	}
+	for _, test := range tests {
+		puz := &puzzle.PuzzleDefinition{Copyright: test.copyright, Author: test.author}
+		if got := Source(puz); got != test.want {
+			t.Errorf("Source(%q, %q) = %q, want %q", test.copyright, test.author, got, test.want)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := Store{Path: filepath.Join(t.TempDir(), "stats", "stats.jsonl")}
+	if solves, err := store.Load(); err != nil || solves != nil {
+		t.Fatalf("loading before anything is recorded = %v, %v", solves, err)
+	}
+	date := time.Date(2024, time.March, 6, 8, 0, 0, 0, time.UTC)
+	first := Solve{Title: "First", Source: "NYT", Date: date, Elapsed: time.Minute, Clean: true}
+	second := Solve{Title: "Second", Source: "LAT", Date: date, Elapsed: time.Hour, Reveals: 1}
+	for _, solve := range []Solve{first, second} {
+		if err := store.Record(solve); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A line that can't be read is skipped rather than losing the history.
+	f, err := os.OpenFile(store.Path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{not json\n\n")
+	f.Close()
+	if err := store.Record(first); err != nil {
+		t.Fatal(err)
+	}
+
+	solves, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.EqualFunc(solves, []Solve{first, second, first}, func(a, b Solve) bool {
+		return a.Title == b.Title && a.Date.Equal(b.Date) && a.Elapsed == b.Elapsed && a.Clean == b.Clean && a.Reveals == b.Reveals
+	}) {
+		t.Errorf("loaded %+v", solves)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                                     "0:00",
+		59*time.Second + 600*time.Millisecond: "1:00",
+		12*time.Minute + 5*time.Second:        "12:05",
+		time.Hour + 2*time.Minute + 3*time.Second: "1:02:03",
+	} {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package theme
 
 import (
+	"fmt"
 	"image/color"
 
 	"github.com/charmbracelet/lipgloss/v2"
@@ -22,6 +23,17 @@ func Init() lipgloss.Style {
 	return theme
 }
 
+// SetTint switches to the tint with the given ID, such as "dracula".
+func SetTint(id string) error {
+	if !tint.SetTintID(id) {
+		return fmt.Errorf("unknown theme %q", id)
+	}
+	theme = lipgloss.NewStyle().
+		Foreground(tint.Fg()).
+		BorderForeground(tint.Fg())
+	return nil
+}
+
 func Get() lipgloss.Style {
 	return theme
 }
@@ -50,6 +62,14 @@ func Secondary() color.Color {
 	return tint.Cyan()
 }
 
+func Tertiary() color.Color {
+	return tint.Purple()
+}
+
+func Muted() color.Color {
+	return tint.BrightBlack()
+}
+
 func Red() color.Color {
 	return tint.Red()
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tylerwgrass/cruciterm/loader"
+)
+
+func runUnlock(cmd *command, args []string) int {
+	fs := newFlagSet(cmd)
+	var global globalOptions
+	global.register(fs)
+	key := fs.Int("key", -1, "four digit unlock key; every key is tried when omitted")
+	output := fs.String("o", "", "output file (default <input>.unlocked.puz)")
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+
+	_, cleanup, err := global.setup()
+	defer cleanup()
+	if err != nil {
+		return fail(cmd, err)
+	}
+
+	input := fs.Arg(0)
+	puz, err := loadPuzzle(input)
+	if err != nil {
+		return fail(cmd, err)
+	}
+
+	if *key < 0 {
+		if *key, err = loader.FindUnlockKey(&puz); err != nil {
+			return fail(cmd, fmt.Errorf("%s: %w", input, err))
+		}
+	}
+	if err := loader.Unlock(&puz, *key); err != nil {
+		return fail(cmd, fmt.Errorf("%s: %w", input, err))
+	}
+
+	if *output == "" {
+		*output = strings.TrimSuffix(input, filepath.Ext(input)) + ".unlocked.puz"
+	}
+	if err := loader.SaveFile(*output, &puz); err != nil {
+		return fail(cmd, fmt.Errorf("%s: %w", *output, err))
+	}
+	fmt.Printf("unlocked with key %04d, wrote %s\n", *key, *output)
+	return exitOK
+}
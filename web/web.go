@@ -0,0 +1,354 @@
+// Package web shows a solve in the browser, e.g. for screen sharing. The page
+// is drawn from the puzzle definition and kept up to date with Server-Sent
+// Events as the solver types. Spectators can be allowed to fill in squares
+// from the page too.
+package web
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"image/color"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/tylerwgrass/cruciterm/logger"
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+var ErrEditsDisabled = errors.New("spectators can't fill in squares")
+var ErrInvalidEdit = errors.New("invalid edit")
+var ErrForeignOrigin = errors.New("edits can only be made from the puzzle's page")
+
+// KEEPALIVE_INTERVAL is how often an idle event stream is written to, so
+// that proxies don't drop it.
+var KEEPALIVE_INTERVAL time.Duration = 15 * time.Second
+
+//go:embed page.html
+var pageSource string
+
+var page = template.Must(template.New("page").Parse(pageSource))
+
+// Snapshot is the grid as the solver last left it.
+type Snapshot struct {
+	State string `json:"state"`
+	Row   int    `json:"row"`
+	Col   int    `json:"col"`
+	Down  bool   `json:"down"`
+	// Wrong and Revealed are squares marked by checks and reveals, by index
+	// into State.
+	Wrong    []int `json:"wrong,omitempty"`
+	Revealed []int `json:"revealed,omitempty"`
+	Solved   bool  `json:"solved"`
+	// Elapsed is the time on the solver's clock when the snapshot was taken,
+	// in milliseconds. The page keeps counting from it until Solved.
+	Elapsed int64 `json:"elapsed"`
+	// Viewers is filled in by the server.
+	Viewers int `json:"viewers"`
+}
+
+// Edit is a square filled in from the page. An empty Fill clears it.
+type Edit struct {
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+	Fill string `json:"fill"`
+}
+
+// Server shows one puzzle to every browser that connects to it.
+type Server struct {
+	listener   net.Listener
+	http       *http.Server
+	puzzle     *puzzle.PuzzleDefinition
+	allowEdits bool
+	edits      chan Edit
+	done       chan struct{}
+
+	mu       sync.Mutex
+	snapshot []byte
+	viewers  map[chan []byte]struct{}
+	closed   bool
+}
+
+// Listen starts showing puz at address. Call Serve to accept browsers.
+func Listen(address string, puz *puzzle.PuzzleDefinition, allowEdits bool) (*Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		listener:   listener,
+		puzzle:     puz,
+		allowEdits: allowEdits,
+		edits:      make(chan Edit),
+		done:       make(chan struct{}),
+		viewers:    make(map[chan []byte]struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handlePage)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("POST /edit", s.handleEdit)
+	s.http = &http.Server{Handler: mux}
+	s.Publish(Snapshot{State: puz.CurrentState})
+	return s, nil
+}
+
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// URL is where to point a browser on this machine.
+func (s *Server) URL() string {
+	host, port, _ := net.SplitHostPort(s.Addr().String())
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// Serve accepts browsers until the server is closed.
+func (s *Server) Serve() error {
+	err := s.http.Serve(s.listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close disconnects every browser and stops accepting new ones.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+	s.mu.Unlock()
+	return s.http.Close()
+}
+
+// Edits delivers the squares spectators fill in, if they are allowed to.
+func (s *Server) Edits() <-chan Edit {
+	return s.edits
+}
+
+// AllowsEdits reports whether spectators can fill in squares.
+func (s *Server) AllowsEdits() bool {
+	return s.allowEdits
+}
+
+// Done is closed once the server is closed.
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// Viewers is how many browsers are following the solve.
+func (s *Server) Viewers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.viewers)
+}
+
+// Publish sends snapshot to every browser.
+func (s *Server) Publish(snapshot Snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot.Viewers = len(s.viewers)
+	s.snapshot, _ = json.Marshal(snapshot)
+	s.broadcast()
+}
+
+// broadcast sends the latest snapshot to every browser. Browsers that are
+// behind skip straight to it. s.mu must be held.
+func (s *Server) broadcast() {
+	for viewer := range s.viewers {
+		select {
+		case <-viewer:
+		default:
+		}
+		viewer <- s.snapshot
+	}
+}
+
+// setViewerCount updates the count in the latest snapshot after a browser
+// comes or goes. s.mu must be held.
+func (s *Server) setViewerCount() {
+	var snapshot Snapshot
+	if err := json.Unmarshal(s.snapshot, &snapshot); err != nil {
+		return
+	}
+	snapshot.Viewers = len(s.viewers)
+	s.snapshot, _ = json.Marshal(snapshot)
+	s.broadcast()
+}
+
+type square struct {
+	Block  bool
+	Number int
+	Circle bool
+}
+
+type pageData struct {
+	Puzzle     *puzzle.PuzzleDefinition
+	Rows       [][]square
+	AllowEdits bool
+	Colors     map[string]template.CSS
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	puz := s.puzzle
+	numbers := make(map[int]int)
+	for _, clues := range [][]*puzzle.Clue{puz.AcrossClues, puz.DownClues} {
+		for _, clue := range clues {
+			numbers[clue.StartRow*puz.NumCols+clue.StartCol] = clue.Num
+		}
+	}
+	rows := make([][]square, puz.NumRows)
+	for i := range rows {
+		rows[i] = make([]square, puz.NumCols)
+		for j := range rows[i] {
+			index := i*puz.NumCols + j
+			rows[i][j] = square{
+				Block:  puz.Answer[index] == '.',
+				Number: numbers[index],
+				Circle: puz.Circles != nil && puz.Circles[index],
+			}
+		}
+	}
+	data := pageData{
+		Puzzle:     puz,
+		Rows:       rows,
+		AllowEdits: s.allowEdits,
+		Colors: map[string]template.CSS{
+			"foreground": hex(theme.Foreground()),
+			"background": hex(theme.Background()),
+			"primary":    hex(theme.Primary()),
+			"secondary":  hex(theme.Secondary()),
+			"tertiary":   hex(theme.Tertiary()),
+			"muted":      hex(theme.Muted()),
+			"red":        hex(theme.Red()),
+			"green":      hex(theme.Green()),
+		},
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := page.Execute(w, data); err != nil {
+		logger.Warn("could not render page", "err", err)
+	}
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{3}([0-9a-fA-F]{3})?$`)
+
+func hex(c color.Color) template.CSS {
+	if c == nil {
+		return "inherit"
+	}
+	// Tints give their colors as hex strings, which don't all report their
+	// RGBA values.
+	if s := fmt.Sprint(c); hexColor.MatchString(s) {
+		return template.CSS(s)
+	}
+	r, g, b, _ := c.RGBA()
+	return template.CSS(fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8))
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	viewer := make(chan []byte, 1)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.viewers[viewer] = struct{}{}
+	s.setViewerCount()
+	s.mu.Unlock()
+	logger.Debug("browser connected", "remote", r.RemoteAddr)
+	defer func() {
+		s.mu.Lock()
+		delete(s.viewers, viewer)
+		s.setViewerCount()
+		s.mu.Unlock()
+		logger.Debug("browser disconnected", "remote", r.RemoteAddr)
+	}()
+
+	keepalive := time.NewTicker(KEEPALIVE_INTERVAL)
+	defer keepalive.Stop()
+	for {
+		select {
+		case data := <-viewer:
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func (s *Server) handleEdit(w http.ResponseWriter, r *http.Request) {
+	if !s.allowEdits {
+		http.Error(w, ErrEditsDisabled.Error(), http.StatusForbidden)
+		return
+	}
+	// Browsers say which page a request comes from, so other pages the
+	// presenter has open can't type into the solve.
+	if origin, err := url.Parse(r.Header.Get("Origin")); err != nil || origin.Host != r.Host {
+		http.Error(w, ErrForeignOrigin.Error(), http.StatusForbidden)
+		return
+	}
+	var edit Edit
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&edit); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.validate(&edit); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	select {
+	case s.edits <- edit:
+		w.WriteHeader(http.StatusNoContent)
+	case <-s.done:
+		http.Error(w, "the solver has closed", http.StatusServiceUnavailable)
+	case <-r.Context().Done():
+	}
+}
+
+// validate checks edit is for a square in the grid, and normalizes its fill
+// the way the solver stores it.
+func (s *Server) validate(edit *Edit) error {
+	puz := s.puzzle
+	if edit.Row < 0 || edit.Row >= puz.NumRows || edit.Col < 0 || edit.Col >= puz.NumCols {
+		return fmt.Errorf("%w: square %d,%d is outside the grid", ErrInvalidEdit, edit.Row, edit.Col)
+	}
+	if puz.Answer[edit.Row*puz.NumCols+edit.Col] == '.' {
+		return fmt.Errorf("%w: square %d,%d is a block", ErrInvalidEdit, edit.Row, edit.Col)
+	}
+	edit.Fill = strings.ToUpper(edit.Fill)
+	if edit.Fill == "" {
+		edit.Fill = "-"
+		return nil
+	}
+	// Squares take a single letter or digit, as they do in the solver.
+	if len(edit.Fill) != 1 || !(unicode.IsLetter(rune(edit.Fill[0])) || unicode.IsDigit(rune(edit.Fill[0]))) {
+		return fmt.Errorf("%w: %q can't go in a square", ErrInvalidEdit, edit.Fill)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tylerwgrass/cruciterm/puzzle"
+	"github.com/tylerwgrass/cruciterm/theme"
+)
+
+func TestEditsOnlyFromThePage(t *testing.T) {
+	theme.Init()
+	puz := &puzzle.PuzzleDefinition{NumRows: 1, NumCols: 2, Answer: "AB", CurrentState: "--"}
+	puz.AssignClues([]string{"1A", "1D", "2D"})
+	s, err := Listen("127.0.0.1:0", puz, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+	go func() {
+		for range s.Edits() {
+		}
+	}()
+
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{s.URL(), http.StatusNoContent},
+		{"", http.StatusForbidden},
+		{"null", http.StatusForbidden},
+		{"http://example.com", http.StatusForbidden},
+		{"http://" + s.Addr().String() + ".example.com", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequest("POST", "http://"+s.Addr().String()+"/edit", strings.NewReader(`{"row": 0, "col": 1, "fill": "b"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.origin != "" {
+			req.Header.Set("Origin", test.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != test.want {
+			t.Errorf("edit from %q: status %d, want %d", test.origin, resp.StatusCode, test.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package wordlist reads the scored word lists grids are filled from. Each
+// line of a list is a word and its score, such as "CRUCIVERBALIST;60", with
+// higher scores for better words.
+package wordlist
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidLine = errors.New("expected WORD;score")
+
+// DEFAULT_SCORE is given to words listed without a score.
+var DEFAULT_SCORE int = 50
+
+type Entry struct {
+	Word  string
+	Score int
+}
+
+// List is a word list, grouped by word length.
+type List struct {
+	byLength map[int][]Entry
+	size     int
+}
+
+// Load reads the word list at path.
+func Load(path string) (*List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	list, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return list, nil
+}
+
+// Parse reads a word list. Words are upper-cased and stripped of anything but
+// letters and digits, so that phrases can be listed with their spaces. Blank
+// lines and lines starting with "#" are skipped, and words listed twice keep
+// their best score.
+func Parse(r io.Reader) (*List, error) {
+	scores := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		text, scoreText, hasScore := strings.Cut(line, ";")
+		score := DEFAULT_SCORE
+		if hasScore {
+			var err error
+			if score, err = strconv.Atoi(strings.TrimSpace(scoreText)); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, ErrInvalidLine)
+			}
+		}
+		word := normalize(text)
+		if word == "" {
+			continue
+		}
+		if best, ok := scores[word]; !ok || score > best {
+			scores[word] = score
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	list := &List{byLength: make(map[int][]Entry), size: len(scores)}
+	for word, score := range scores {
+		list.byLength[len(word)] = append(list.byLength[len(word)], Entry{Word: word, Score: score})
+	}
+	for _, entries := range list.byLength {
+		slices.SortFunc(entries, func(a, b Entry) int {
+			return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.Word, b.Word))
+		})
+	}
+	return list, nil
+}
+
+// normalize keeps the ASCII letters and digits of word, upper-cased, as
+// squares hold. Words with other letters can't go in a grid and come back
+// empty.
+func normalize(word string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(word) {
+		switch {
+		case r >= 'A' && r <= 'Z' || r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case r > 0x7F:
+			return ""
+		}
+	}
+	return sb.String()
+}
+
+// Words lists the words with length letters, best first.
+func (l *List) Words(length int) []Entry {
+	return l.byLength[length]
+}
+
+// Len is the number of words in the list.
+func (l *List) Len() int {
+	return l.size
+}
@@ -0,0 +1,78 @@
+package wordlist
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  map[int][]Entry
+	}{
+		{
+			name:  "scored words",
+			lines: []string{"CAT;60", "DOG;70", "HORSE;55"},
+			want:  map[int][]Entry{3: {{"DOG", 70}, {"CAT", 60}}, 5: {{"HORSE", 55}}},
+		},
+		{
+			name:  "comments and blank lines",
+			lines: []string{"# animals", "", "CAT;60", "   ", "  # DOG;70", "#EMU;80"},
+			want:  map[int][]Entry{3: {{"CAT", 60}}},
+		},
+		{
+			name:  "duplicates keep their best score",
+			lines: []string{"cat;10", "CAT;70", "C A T;30", "c-a-t"},
+			want:  map[int][]Entry{3: {{"CAT", 70}}},
+		},
+		{
+			name:  "missing scores are the default",
+			lines: []string{"CAT", "DOG;51", "EMU;49"},
+			want:  map[int][]Entry{3: {{"DOG", 51}, {"CAT", 50}, {"EMU", 49}}},
+		},
+		{
+			name:  "ties are alphabetical",
+			lines: []string{"EMU;50", "CAT;50", "DOG;50"},
+			want:  map[int][]Entry{3: {{"CAT", 50}, {"DOG", 50}, {"EMU", 50}}},
+		},
+		{
+			name:  "phrases and punctuation",
+			lines: []string{"ice cream;55", "O'CLOCK;40", "r2-d2; 45"},
+			want:  map[int][]Entry{4: {{"R2D2", 45}}, 6: {{"OCLOCK", 40}}, 8: {{"ICECREAM", 55}}},
+		},
+		{
+			name:  "non-ASCII words are dropped",
+			lines: []string{"CAFÉ;80", "naïve;70", "CAFE;60", "...;90"},
+			want:  map[int][]Entry{4: {{"CAFE", 60}}},
+		},
+	}
+	for _, test := range tests {
+		list, err := Parse(strings.NewReader(strings.Join(test.lines, "\n")))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		size := 0
+		for length, want := range test.want {
+			size += len(want)
+			if got := list.Words(length); !slices.Equal(got, want) {
+				t.Errorf("%s: Words(%d) = %v, want %v", test.name, length, got, want)
+			}
+		}
+		if list.Len() != size {
+			t.Errorf("%s: Len() = %d, want %d", test.name, list.Len(), size)
+		}
+	}
+}
+
+func TestParseInvalidScore(t *testing.T) {
+	for _, text := range []string{"CAT;60\nDOG;lots", "CAT;60\nDOG;"} {
+		_, err := Parse(strings.NewReader(text))
+		if !errors.Is(err, ErrInvalidLine) || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Parse(%q): err = %v, want ErrInvalidLine on line 2", text, err)
+		}
+	}
+}